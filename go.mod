@@ -1,3 +1,11 @@
 module github.com/Attumm/settingo
 
-go 1.17
+go 1.18
+
+require (
+	github.com/BurntSushi/toml v1.2.1
+	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.30.0 // indirect
@@ -0,0 +1,117 @@
+package settingo
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+// SetAlias registers additional flag names for a setting, such as the
+// shorthand "p" for "port". Single letter aliases can be grouped on the
+// command line: -vq sets both -v and -q, and -p8080 gives -p the value 8080.
+func (s *Settings) SetAlias(flagName string, aliases ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.key(flagName)
+	for _, alias := range aliases {
+		s.aliases[alias] = key
+	}
+}
+
+// canonical returns the setting key a flag name refers to.
+func (s *Settings) canonical(name string) string {
+	if key, found := s.aliases[name]; found {
+		return key
+	}
+	return name
+}
+
+// registerAliases defines the aliases on fs, sharing the flag.Value of the
+// setting so that the last of the names given on the command line wins.
+func (s *Settings) registerAliases(fs *flag.FlagSet) {
+	aliases := make([]string, 0, len(s.aliases))
+	for alias := range s.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		key := s.aliases[alias]
+		target := fs.Lookup(key)
+		if target == nil || fs.Lookup(alias) != nil {
+			continue
+		}
+		if value, ok := target.Value.(*flagValue); !ok || value.owner != s.owner() {
+			continue
+		}
+		fs.Var(target.Value, alias, "alias for -"+key)
+	}
+}
+
+// takesValue reports whether the flag package reads the argument after f as its value.
+func takesValue(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !boolFlag.IsBoolFlag()
+}
+
+// expandShortFlags rewrites grouped single letter flags into the form the
+// flag package understands: -vq becomes -v=true -q=true and -p8080 becomes
+// -p=8080. Rewriting stops where flag parsing stops, at "--" or the first
+// argument that is not a flag.
+func (s *Settings) expandShortFlags(fs *flag.FlagSet, args []string) []string {
+	expanded := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			return append(expanded, args[i:]...)
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		f := fs.Lookup(name)
+		if f == nil && !strings.HasPrefix(arg, "--") {
+			if group, last, ok := expandGroup(fs, arg[1:]); ok {
+				arg, f = group[len(group)-1], last
+				expanded = append(expanded, group[:len(group)-1]...)
+				hasValue = strings.Contains(arg, "=")
+			}
+		}
+		expanded = append(expanded, arg)
+		if f != nil && !hasValue && takesValue(f) && i+1 < len(args) {
+			expanded = append(expanded, args[i+1])
+			i++
+		}
+	}
+	return expanded
+}
+
+// expandGroup splits a group of single letter flags. It returns the
+// rewritten flags and the last flag of the group, or false when a letter is
+// not a flag, leaving the argument for the flag package to report.
+func expandGroup(fs *flag.FlagSet, group string) ([]string, *flag.Flag, bool) {
+	var expanded []string
+	for i, r := range group {
+		letter := string(r)
+		f := fs.Lookup(letter)
+		if f == nil {
+			return nil, nil, false
+		}
+		rest := group[i+len(letter):]
+		if !takesValue(f) {
+			if strings.HasPrefix(rest, "=") {
+				return append(expanded, "-"+letter+rest), f, true
+			}
+			expanded = append(expanded, "-"+letter+"=true")
+			if rest == "" {
+				return expanded, f, true
+			}
+			continue
+		}
+		if rest == "" {
+			return append(expanded, "-"+letter), f, true
+		}
+		return append(expanded, "-"+letter+"="+strings.TrimPrefix(rest, "=")), f, true
+	}
+	return nil, nil, false
+}
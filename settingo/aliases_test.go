@@ -0,0 +1,90 @@
+package settingo
+
+import (
+	"strings"
+	"testing"
+)
+
+func newAliasSettings() *Settings {
+	s := New()
+	s.SetInt("port", 8080, "port")
+	s.SetBool("verbose", false, "verbose")
+	s.SetBool("quiet", false, "quiet")
+	s.Set("name", "", "name")
+	s.SetAlias("port", "p", "listen-port")
+	s.SetAlias("verbose", "v")
+	s.SetAlias("quiet", "q")
+	s.SetAlias("name", "n")
+	return s
+}
+
+func TestAliases(t *testing.T) {
+	cases := []struct {
+		args    []string
+		port    int
+		verbose bool
+		quiet   bool
+		rest    string
+	}{
+		{[]string{"-p", "9000"}, 9000, false, false, ""},
+		{[]string{"--port", "9000"}, 9000, false, false, ""},
+		{[]string{"--port=9000"}, 9000, false, false, ""},
+		{[]string{"--listen-port=9000"}, 9000, false, false, ""},
+		{[]string{"-p9000"}, 9000, false, false, ""},
+		{[]string{"-vq"}, 8080, true, true, ""},
+		{[]string{"-vqp9000", "file"}, 9000, true, true, "file"},
+		{[]string{"-vp", "9000", "-q=false"}, 9000, true, false, ""},
+		{[]string{"-v=true", "--", "-q"}, 8080, true, false, "-q"},
+		{[]string{"file", "-vq"}, 8080, false, false, "file -vq"},
+	}
+	for _, c := range cases {
+		s := newAliasSettings()
+		if err := s.ParseArgs(c.args); err != nil {
+			t.Error(c.args, err)
+			continue
+		}
+		if got := s.GetInt("port"); got != c.port {
+			t.Error(c.args, ": ", got, " != ", c.port)
+		}
+		if got := s.GetBool("verbose"); got != c.verbose {
+			t.Error(c.args, ": ", got, " != ", c.verbose)
+		}
+		if got := s.GetBool("quiet"); got != c.quiet {
+			t.Error(c.args, ": ", got, " != ", c.quiet)
+		}
+		if got := strings.Join(s.Args(), " "); got != c.rest {
+			t.Error(c.args, ": ", got, " != ", c.rest)
+		}
+	}
+}
+
+func TestAliasSource(t *testing.T) {
+	s := newAliasSettings()
+	if err := s.ParseArgs([]string{"-n", "svc", "-xv"}); err == nil {
+		t.Error("expected an error for the unknown flag in -xv")
+	}
+
+	s = newAliasSettings()
+	if err := s.ParseArgs([]string{"-nsvc"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get("name"); got != "svc" {
+		t.Error(got, " != ", "svc")
+	}
+	if got := s.Source("name").String(); got != "flag -n" {
+		t.Error(got, " != ", "flag -n")
+	}
+}
+
+func TestAliasSourceAfterReload(t *testing.T) {
+	s := newAliasSettings()
+	if err := s.ParseArgs([]string{"-n", "svc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Source("name").String(); got != "flag -n" {
+		t.Error(got, " != ", "flag -n")
+	}
+}
@@ -0,0 +1,107 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTruthiness(t *testing.T) {
+	for _, raw := range []string{"1", "t", "true", "TRUE", "y", "Yes", "on", "ON"} {
+		if truth, err := truthiness(raw); !truth || err != nil {
+			t.Error(raw, ": ", truth, err)
+		}
+	}
+	for _, raw := range []string{"", "0", "f", "False", "n", "no", "off", "OFF"} {
+		if truth, err := truthiness(raw); truth || err != nil {
+			t.Error(raw, ": ", truth, err)
+		}
+	}
+	for _, raw := range []string{"maybe", "2", "enabled"} {
+		if _, err := truthiness(raw); err == nil {
+			t.Error(raw, " should not be accepted")
+		}
+	}
+}
+
+func TestBoolFlags(t *testing.T) {
+	cases := []struct {
+		args    []string
+		verbose bool
+		rest    int
+	}{
+		{[]string{"-verbose"}, true, 0},
+		{[]string{"--verbose", "file"}, true, 1},
+		{[]string{"-verbose=false"}, false, 0},
+		{[]string{"-verbose=off"}, false, 0},
+		{[]string{"--no-verbose"}, false, 0},
+		{[]string{"-no-verbose=false"}, true, 0},
+		{[]string{"-verbose", "-no-verbose"}, false, 0},
+		{[]string{"-no-verbose", "-verbose"}, true, 0},
+	}
+	for _, c := range cases {
+		s := New()
+		s.SetBool("verbose", !c.verbose, "verbose")
+		if err := s.ParseArgs(c.args); err != nil {
+			t.Error(c.args, err)
+			continue
+		}
+		if got := s.GetBool("verbose"); got != c.verbose {
+			t.Error(c.args, ": ", got, " != ", c.verbose)
+		}
+		if got := len(s.Args()); got != c.rest {
+			t.Error(c.args, ": ", got, " != ", c.rest)
+		}
+		if got := s.Source("verbose").Kind; got != SourceFlag {
+			t.Error(c.args, ": ", got, " != ", SourceFlag)
+		}
+	}
+}
+
+func TestBoolInvalidInput(t *testing.T) {
+	os.Setenv("BOOLDEBUG", "maybe")
+	defer os.Unsetenv("BOOLDEBUG")
+
+	s := New()
+	s.SetBool("booldebug", true, "debug")
+	err := s.ParseArgs(nil)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 {
+		t.Fatalf("expected one ParseError, got %v", err)
+	}
+	if !s.GetBool("booldebug") {
+		t.Error("invalid input should keep the default")
+	}
+
+	if err := s.ParseArgs([]string{"-no-booldebug=maybe"}); err == nil {
+		t.Error("expected an error for an invalid negation")
+	}
+}
+
+func TestBoolSeparateValueRejected(t *testing.T) {
+	for _, args := range [][]string{
+		{"-verbose", "false"}, {"--verbose", "TRUE"}, {"-v", "false"},
+		{"-verbose", "no"}, {"-verbose", "0"}, {"-verbose", "OFF"}, {"-verbose", "n"}, {"-verbose", "yes"},
+	} {
+		s := New()
+		s.SetBool("verbose", false, "verbose")
+		s.SetAlias("verbose", "v")
+		out := captureStderr(t, func() {
+			if err := s.ParseArgs(args); err == nil {
+				t.Error(args, " accepted")
+			}
+		})
+		if !strings.Contains(out, args[0]+"="+args[1]) {
+			t.Error(out, " does not suggest ", args[0]+"="+args[1])
+		}
+	}
+
+	for _, args := range [][]string{{"-verbose", "--", "false"}, {"-verbose=true", "false"}, {"-verbose", "file"}} {
+		s := New()
+		s.SetBool("verbose", false, "verbose")
+		if err := s.ParseArgs(args); err != nil {
+			t.Error(args, err)
+		}
+	}
+}
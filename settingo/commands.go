@@ -0,0 +1,156 @@
+package settingo
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Command is a subcommand of a program, such as "serve" in "tool serve".
+//
+// Every command has its own Settings, read from the environment and from the
+// flags that follow the command name. Run is called with the arguments left
+// after those flags. Commands can have subcommands of their own, added with
+// the AddCommand method of their Settings.
+type Command struct {
+	Name     string
+	Help     string
+	Settings *Settings
+	Run      func(args []string) error
+}
+
+// AddCommand adds cmd as a subcommand. When cmd has no Settings, one is
+// created with the env prefix, fallback, name mapper and casing of s.
+func (s *Settings) AddCommand(cmd *Command) *Command {
+	s.mu.Lock()
+	if cmd.Settings == nil {
+		cmd.Settings = New(
+			WithContextualCasing(s.ContextualCasing),
+			WithEnvPrefix(s.envPrefix),
+			WithNameMapper(s.nameMapper),
+		)
+		cmd.Settings.envFallback = s.envFallback
+	}
+	name := s.programName() + " " + cmd.Name
+	s.commands = append(s.commands, cmd)
+	s.mu.Unlock()
+
+	// A parse of cmd locks s after cmd, so the two are never locked the other way around.
+	cmd.Settings.mu.Lock()
+	defer cmd.Settings.mu.Unlock()
+	cmd.Settings.parent = s
+	cmd.Settings.name = name
+	return cmd
+}
+
+// MarkPersistent makes settings available to every subcommand: their flags
+// are also accepted after the name of a subcommand, at any depth.
+func (s *Settings) MarkPersistent(flagNames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, flagName := range flagNames {
+		s.persistent[s.key(flagName)] = true
+	}
+}
+
+// Execute parses args and runs the subcommand they name.
+//
+// The flags before the first argument belong to s, the flags after a command
+// name to that command, and so on down the tree. The Run function of the last
+// command found receives the remaining arguments. Without subcommands Execute
+// is the same as ParseArgs.
+func (s *Settings) Execute(args []string) error {
+	if err := s.ParseArgs(args); err != nil {
+		return err
+	}
+	return s.dispatch(s.Args())
+}
+
+// dispatch runs the subcommand named by args[0].
+func (s *Settings) dispatch(args []string) error {
+	s.mu.RLock()
+	commands := s.commands
+	name := s.programName()
+	s.mu.RUnlock()
+	if len(commands) == 0 {
+		return nil
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("settingo: %s: missing command, expected one of %s", name, commandNames(commands))
+	}
+	var cmd *Command
+	for _, candidate := range commands {
+		if candidate.Name == args[0] {
+			cmd = candidate
+		}
+	}
+	if cmd == nil {
+		candidates := make([]string, len(commands))
+		for i, candidate := range commands {
+			candidates[i] = candidate.Name
+		}
+		if suggestions := suggest(args[0], candidates); len(suggestions) > 0 {
+			return fmt.Errorf("settingo: %s: unknown command %q, did you mean %s?", name, args[0], strings.Join(suggestions, " or "))
+		}
+		return fmt.Errorf("settingo: %s: unknown command %q, expected one of %s", name, args[0], commandNames(commands))
+	}
+	if err := cmd.Settings.ParseArgs(args[1:]); err != nil {
+		return err
+	}
+	rest := cmd.Settings.Args()
+	if cmd.Run == nil || (len(rest) > 0 && cmd.Settings.hasCommand(rest[0])) {
+		return cmd.Settings.dispatch(rest)
+	}
+	return cmd.Run(rest)
+}
+
+func (s *Settings) hasCommand(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, cmd := range s.commands {
+		if cmd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func commandNames(commands []*Command) string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ancestors returns the Settings of the parent commands, nearest first.
+func (s *Settings) ancestors() []*Settings {
+	var ancestors []*Settings
+	for parent := s.parent; parent != nil; parent = parent.parent {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// programName is the name help output is given for, "tool serve" for a subcommand.
+func (s *Settings) programName() string {
+	if s.name != "" {
+		return s.name
+	}
+	return os.Args[0]
+}
+
+// help returns the help text of the command s belongs to.
+func (s *Settings) help() string {
+	if s.parent == nil {
+		return ""
+	}
+	for _, cmd := range s.parent.commands {
+		if cmd.Settings == s {
+			return cmd.Help
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package settingo
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newCommandTree(ran *string, got *[]string) (*Settings, *Command, *Command) {
+	root := New()
+	root.SetBool("verbose", false, "verbose")
+	root.Set("region", "eu", "region")
+	root.MarkPersistent("verbose")
+
+	serve := root.AddCommand(&Command{
+		Name: "serve",
+		Help: "Start the server",
+		Run: func(args []string) error {
+			*ran, *got = "serve", args
+			return nil
+		},
+	})
+	serve.Settings.SetInt("cmdport", 8080, "port")
+
+	migrate := root.AddCommand(&Command{Name: "migrate", Help: "Manage the schema"})
+	up := migrate.Settings.AddCommand(&Command{
+		Name: "up",
+		Run: func(args []string) error {
+			*ran, *got = "up", args
+			return nil
+		},
+	})
+	up.Settings.SetInt("steps", 1, "steps")
+	return root, serve, up
+}
+
+func TestCommands(t *testing.T) {
+	var ran string
+	var got []string
+
+	root, serve, _ := newCommandTree(&ran, &got)
+	if err := root.Execute([]string{"-region=us", "serve", "-cmdport=9000", "-verbose", "file"}); err != nil {
+		t.Fatal(err)
+	}
+	if ran != "serve" || strings.Join(got, " ") != "file" {
+		t.Error(ran, got, " != ", "serve [file]")
+	}
+	if got := serve.Settings.GetInt("cmdport"); got != 9000 {
+		t.Error(got, " != ", 9000)
+	}
+	if !root.GetBool("verbose") {
+		t.Error("persistent flag after the command was not stored in the parent")
+	}
+	if got := root.Get("region"); got != "us" {
+		t.Error(got, " != ", "us")
+	}
+
+	root, _, up := newCommandTree(&ran, &got)
+	os.Setenv("STEPS", "3")
+	defer os.Unsetenv("STEPS")
+	if err := root.Execute([]string{"migrate", "up", "--verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if ran != "up" {
+		t.Error(ran, " != ", "up")
+	}
+	if got := up.Settings.GetInt("steps"); got != 3 {
+		t.Error(got, " != ", 3)
+	}
+	if !root.GetBool("verbose") {
+		t.Error("persistent flag was not inherited two levels down")
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	var ran string
+	var got []string
+
+	root, _, _ := newCommandTree(&ran, &got)
+	if err := root.Execute(nil); err == nil || !strings.Contains(err.Error(), "missing command") {
+		t.Error("expected a missing command error, got", err)
+	}
+	if err := root.Execute([]string{"deploy"}); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Error("expected an unknown command error, got", err)
+	}
+	if err := root.Execute([]string{"migrate"}); err == nil {
+		t.Error("expected a missing command error for migrate")
+	}
+	if err := root.Execute([]string{"serve", "-region=us"}); err == nil {
+		t.Error("a setting that is not persistent should not be accepted by a subcommand")
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	root := New()
+	root.name = "tool"
+	serve := root.AddCommand(&Command{Name: "serve", Help: "Start the server"})
+	serve.Settings.SetInt("port", 8080, "Port to listen on")
+
+	var out bytes.Buffer
+	fs := serve.Settings.newFlagSet(flag.ContinueOnError)
+	fs.SetOutput(&out)
+	serve.Settings.defineFlags(fs, nil)
+	fs.Usage()
+	for _, want := range []string{"Usage of tool serve:", "Start the server", "Port to listen on"} {
+		if !strings.Contains(out.String(), want) {
+			t.Error(out.String(), " does not contain ", want)
+		}
+	}
+
+	out.Reset()
+	fs = root.newFlagSet(flag.ContinueOnError)
+	fs.SetOutput(&out)
+	fs.Usage()
+	if !strings.Contains(out.String(), "serve  Start the server") {
+		t.Error(out.String(), " does not list the commands")
+	}
+}
+
+func TestCommandHelpFlag(t *testing.T) {
+	var ran string
+	var got []string
+	root, _, _ := newCommandTree(&ran, &got)
+	out := captureStderr(t, func() {
+		if err := root.Execute([]string{"serve", "-help"}); err != flag.ErrHelp {
+			t.Error(err, " != ", flag.ErrHelp)
+		}
+	})
+	for _, want := range []string{" serve:\n", "env VERBOSE"} {
+		if !strings.Contains(out, want) {
+			t.Error(out, " does not contain ", want)
+		}
+	}
+}
+
+func TestCommandPersistentFlagUnparsedParent(t *testing.T) {
+	root := New(WithSources(SourceEnv))
+	root.SetBool("verbose", false, "verbose")
+	root.MarkPersistent("verbose")
+	root.AddCommand(&Command{Name: "serve"})
+	if err := root.Execute([]string{"serve", "-verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if !root.GetBool("verbose") {
+		t.Error(root.GetBool("verbose"), " != ", true)
+	}
+
+	root = New()
+	root.SetBool("verbose", false, "verbose")
+	root.MarkPersistent("verbose")
+	serve := root.AddCommand(&Command{Name: "serve"})
+	if err := serve.Settings.ParseArgs([]string{"-verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if !root.GetBool("verbose") {
+		t.Error(root.GetBool("verbose"), " != ", true)
+	}
+}
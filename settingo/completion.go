@@ -0,0 +1,413 @@
+package settingo
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completion is how the shell completes the value of a setting.
+type completion struct {
+	values []string // fixed values, from SetChoices or a oneof rule
+	files  bool
+	dirs   bool
+	exts   []string // extensions completed files are limited to, without the dot
+}
+
+// SetChoices restricts a setting to values, adding a OneOf rule, and
+// completes the values in the generated shell completion.
+//
+// Example:
+//
+//	s.Set("loglevel", "info", "Log level")
+//	s.SetChoices("loglevel", "debug", "info", "warn", "error")
+func (s *Settings) SetChoices(flagName string, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.key(flagName)
+	s.rules[key] = append(s.rules[key], OneOf(values...))
+	comp := s.completions[key]
+	comp.values = values
+	s.completions[key] = comp
+}
+
+// SetFileCompletion completes the value of a setting with file paths,
+// limited to the given extensions when there are any.
+//
+// Example:
+//
+//	s.SetFileCompletion("cert", "pem", "crt")
+func (s *Settings) SetFileCompletion(flagName string, extensions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completions[s.key(flagName)] = completion{files: true, exts: extensions}
+}
+
+// SetDirCompletion completes the value of a setting with directory paths.
+func (s *Settings) SetDirCompletion(flagName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completions[s.key(flagName)] = completion{dirs: true}
+}
+
+// parseCompletion parses the complete struct tag: "file", "file=yaml yml" or "dir".
+func parseCompletion(tag string) (completion, error) {
+	name, arg, _ := strings.Cut(tag, "=")
+	switch strings.TrimSpace(name) {
+	case "file":
+		return completion{files: true, exts: strings.Fields(arg)}, nil
+	case "dir":
+		return completion{dirs: true}, nil
+	}
+	return completion{}, fmt.Errorf("unknown completion %q", tag)
+}
+
+// completionFlag is a flag as the shell completes it.
+type completionFlag struct {
+	names      []string // the flag name followed by its aliases, without dashes
+	help       string
+	takesValue bool
+	completion completion
+}
+
+// completionCommand holds the flags and subcommands of the program or of one
+// of its subcommands, path is "" for the program and e.g. "db migrate" otherwise.
+type completionCommand struct {
+	path     string
+	flags    []completionFlag
+	commands []UsageCommand
+}
+
+// GenerateCompletion writes a completion script for shell, which is "bash",
+// "zsh" or "fish". The script completes the flags and subcommands of the
+// program, the values of settings with choices and file or directory paths.
+//
+// Example, with the script written by `myapp completion bash`:
+//
+//	source <(myapp completion bash)
+func (s *Settings) GenerateCompletion(w io.Writer, shell string) error {
+	root := s
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.mu.RLock()
+	program := filepath.Base(strings.Fields(root.programName() + " settingo")[0])
+	root.mu.RUnlock()
+	commands := root.completionCommands("", nil)
+
+	switch shell {
+	case "bash":
+		return writeBashCompletion(w, program, commands)
+	case "zsh":
+		return writeZshCompletion(w, program, commands)
+	case "fish":
+		return writeFishCompletion(w, program, commands)
+	}
+	return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", shell)
+}
+
+// completionCommands collects the flags of s and its subcommands. inherited
+// are the persistent flags of the parent commands. Only one Settings is
+// locked at a time.
+func (s *Settings) completionCommands(path string, inherited []completionFlag) []completionCommand {
+	s.mu.RLock()
+	cmd := completionCommand{path: path}
+	var persistent []completionFlag
+	seen := make(map[string]bool)
+	for _, key := range s.names() {
+		flags := s.completionFlags(key)
+		for _, f := range flags {
+			seen[f.names[0]] = true
+		}
+		cmd.flags = append(cmd.flags, flags...)
+		if s.persistent[key] {
+			persistent = append(persistent, flags...)
+		}
+	}
+	for _, f := range inherited {
+		if !seen[f.names[0]] {
+			cmd.flags = append(cmd.flags, f)
+		}
+	}
+	children := make([]*Command, len(s.commands))
+	copy(children, s.commands)
+	s.mu.RUnlock()
+
+	commands := []completionCommand{cmd}
+	inherited = append(persistent, inherited...)
+	for _, child := range children {
+		commands[0].commands = append(commands[0].commands, UsageCommand{Name: child.Name, Help: child.Help})
+		commands = append(commands, child.Settings.completionCommands(strings.TrimSpace(path+" "+child.Name), inherited)...)
+	}
+	return commands
+}
+
+// completionFlags returns the flag of key and, for a bool setting, its -no- negation.
+func (s *Settings) completionFlags(key string) []completionFlag {
+	names := []string{key}
+	var aliases []string
+	for alias, target := range s.aliases {
+		if target == key {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	names = append(names, aliases...)
+
+	_, isBool := s.VarBool[key]
+	flags := []completionFlag{{names: names, help: s.msg[key], takesValue: !isBool, completion: s.completions[key]}}
+	if negated := "no-" + key; isBool && !s.nameInUse(negated) {
+		flags = append(flags, completionFlag{names: []string{negated}, help: "set -" + key + " to false"})
+	}
+	return flags
+}
+
+// identifier turns the program name into a shell function name.
+func identifier(program string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, program)
+}
+
+// shellQuote quotes word for bash and zsh.
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes word for fish.
+func fishQuote(word string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(word) + "'"
+}
+
+// completionWords returns the words completed for a command: its flags and subcommands.
+func completionWords(cmd completionCommand) []string {
+	var words []string
+	for _, f := range cmd.flags {
+		for _, name := range f.names {
+			words = append(words, "-"+name)
+		}
+	}
+	for _, sub := range cmd.commands {
+		words = append(words, sub.Name)
+	}
+	return words
+}
+
+func commandPaths(commands []completionCommand) []string {
+	var paths []string
+	for _, cmd := range commands[1:] {
+		paths = append(paths, cmd.path)
+	}
+	return paths
+}
+
+func writeBashCompletion(w io.Writer, program string, commands []completionCommand) error {
+	fn := "_" + identifier(program) + "_completion"
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s, generated by settingo.\n", program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" cmd=\"\" next i ext\n")
+	if paths := commandPaths(commands); len(paths) > 0 {
+		b.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+		b.WriteString("\t\tnext=\"${cmd:+$cmd }${COMP_WORDS[i]}\"\n")
+		b.WriteString("\t\tcase \"$next\" in\n")
+		fmt.Fprintf(&b, "\t\t%s) cmd=\"$next\" ;;\n", quoteAll(paths, shellQuote, "|"))
+		b.WriteString("\t\tesac\n")
+		b.WriteString("\tdone\n")
+	}
+	b.WriteString("\tCOMPREPLY=()\n")
+	b.WriteString("\tcase \"$cmd\" in\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "\t%s)\n", shellQuote(cmd.path))
+		b.WriteString("\t\tcase \"$prev\" in\n")
+		for _, f := range cmd.flags {
+			if !f.takesValue {
+				continue
+			}
+			var patterns []string
+			for _, name := range f.names {
+				patterns = append(patterns, "-"+name, "--"+name)
+			}
+			fmt.Fprintf(&b, "\t\t%s)\n", quoteAll(patterns, shellQuote, "|"))
+			comp := f.completion
+			switch {
+			case len(comp.values) > 0:
+				fmt.Fprintf(&b, "\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(comp.values, " ")))
+			case comp.dirs:
+				b.WriteString("\t\t\tcompopt -o filenames\n")
+				b.WriteString("\t\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n")
+			case comp.files && len(comp.exts) > 0:
+				b.WriteString("\t\t\tcompopt -o filenames\n")
+				b.WriteString("\t\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n")
+				fmt.Fprintf(&b, "\t\t\tfor ext in %s; do\n", quoteAll(comp.exts, shellQuote, " "))
+				b.WriteString("\t\t\t\tCOMPREPLY+=($(compgen -f -X \"!*.$ext\" -- \"$cur\"))\n")
+				b.WriteString("\t\t\tdone\n")
+			case comp.files:
+				b.WriteString("\t\t\tcompopt -o filenames\n")
+				b.WriteString("\t\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			}
+			b.WriteString("\t\t\treturn\n")
+			b.WriteString("\t\t\t;;\n")
+		}
+		b.WriteString("\t\tesac\n")
+		fmt.Fprintf(&b, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(completionWords(cmd), " ")))
+		b.WriteString("\t\t;;\n")
+	}
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, shellQuote(program))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZshCompletion(w io.Writer, program string, commands []completionCommand) error {
+	fn := "_" + identifier(program)
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", program)
+	fmt.Fprintf(&b, "# zsh completion for %s, generated by settingo.\n", program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("\tlocal cmd=\"\" next i\n")
+	b.WriteString("\tlocal -a candidates\n")
+	if paths := commandPaths(commands); len(paths) > 0 {
+		b.WriteString("\tfor ((i = 2; i < CURRENT; i++)); do\n")
+		b.WriteString("\t\tnext=\"${cmd:+$cmd }${words[i]}\"\n")
+		b.WriteString("\t\tcase \"$next\" in\n")
+		fmt.Fprintf(&b, "\t\t(%s) cmd=\"$next\" ;;\n", quoteAll(paths, shellQuote, "|"))
+		b.WriteString("\t\tesac\n")
+		b.WriteString("\tdone\n")
+	}
+	b.WriteString("\tcase \"$cmd\" in\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "\t(%s)\n", shellQuote(cmd.path))
+		b.WriteString("\t\tcase \"${words[CURRENT-1]}\" in\n")
+		for _, f := range cmd.flags {
+			if !f.takesValue {
+				continue
+			}
+			var patterns []string
+			for _, name := range f.names {
+				patterns = append(patterns, "-"+name, "--"+name)
+			}
+			fmt.Fprintf(&b, "\t\t(%s)\n", quoteAll(patterns, shellQuote, "|"))
+			comp := f.completion
+			switch {
+			case len(comp.values) > 0:
+				fmt.Fprintf(&b, "\t\t\tcompadd -- %s\n", quoteAll(comp.values, shellQuote, " "))
+			case comp.dirs:
+				b.WriteString("\t\t\t_files -/\n")
+			case comp.files && len(comp.exts) > 0:
+				fmt.Fprintf(&b, "\t\t\t_files -g %s\n", shellQuote("*.("+strings.Join(comp.exts, "|")+")"))
+			case comp.files:
+				b.WriteString("\t\t\t_files\n")
+			}
+			b.WriteString("\t\t\treturn\n")
+			b.WriteString("\t\t\t;;\n")
+		}
+		b.WriteString("\t\tesac\n")
+		var described []string
+		for _, f := range cmd.flags {
+			for _, name := range f.names {
+				described = append(described, zshDescribed("-"+name, f.help))
+			}
+		}
+		for _, sub := range cmd.commands {
+			described = append(described, zshDescribed(sub.Name, sub.Help))
+		}
+		fmt.Fprintf(&b, "\t\tcandidates=(%s)\n", strings.Join(described, " "))
+		b.WriteString("\t\t_describe 'flag or command' candidates\n")
+		b.WriteString("\t\t;;\n")
+	}
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n")
+	// Run directly when autoloaded from fpath, register when sourced.
+	fmt.Fprintf(&b, "if [ \"$funcstack[1]\" = %s ]; then\n", shellQuote(fn))
+	fmt.Fprintf(&b, "\t%s \"$@\"\n", fn)
+	b.WriteString("else\n")
+	fmt.Fprintf(&b, "\tcompdef %s %s\n", fn, shellQuote(program))
+	b.WriteString("fi\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshDescribed formats a word and its description for _describe.
+func zshDescribed(word, help string) string {
+	word = strings.ReplaceAll(word, ":", `\:`)
+	if help == "" {
+		return shellQuote(word)
+	}
+	return shellQuote(word + ":" + strings.Join(strings.Fields(help), " "))
+}
+
+func writeFishCompletion(w io.Writer, program string, commands []completionCommand) error {
+	fn := "__" + identifier(program) + "_command"
+	quoted := fishQuote(program)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s, generated by settingo.\n", program)
+	fmt.Fprintf(&b, "function %s\n", fn)
+	b.WriteString("\tset -l cmd ''\n")
+	if paths := commandPaths(commands); len(paths) > 0 {
+		b.WriteString("\tfor word in (commandline -opc)[2..-1]\n")
+		b.WriteString("\t\tset -l next (string trim -- \"$cmd $word\")\n")
+		fmt.Fprintf(&b, "\t\tif contains -- $next %s\n", quoteAll(paths, fishQuote, " "))
+		b.WriteString("\t\t\tset cmd $next\n")
+		b.WriteString("\t\tend\n")
+		b.WriteString("\tend\n")
+	}
+	b.WriteString("\ttest \"$cmd\" = \"$argv[1]\"\n")
+	b.WriteString("end\n")
+	fmt.Fprintf(&b, "complete -c %s -f\n", quoted)
+	for _, cmd := range commands {
+		condition := fishQuote(fn + " " + fishQuote(cmd.path))
+		for _, f := range cmd.flags {
+			line := fmt.Sprintf("complete -c %s -n %s", quoted, condition)
+			for _, name := range f.names {
+				line += " -o " + fishQuote(name)
+			}
+			comp := f.completion
+			switch {
+			case !f.takesValue:
+			case len(comp.values) > 0:
+				line += " -x -a " + fishQuote(strings.Join(comp.values, " "))
+			case comp.dirs:
+				line += " -x -a '(__fish_complete_directories)'"
+			case comp.files && len(comp.exts) > 0:
+				var calls []string
+				for _, ext := range comp.exts {
+					calls = append(calls, "__fish_complete_suffix ."+ext)
+				}
+				line += " -x -a " + fishQuote("("+strings.Join(calls, "; ")+")")
+			case comp.files:
+				line += " -r -F"
+			default:
+				line += " -x"
+			}
+			if f.help != "" {
+				line += " -d " + fishQuote(strings.Join(strings.Fields(f.help), " "))
+			}
+			b.WriteString(line + "\n")
+		}
+		for _, sub := range cmd.commands {
+			line := fmt.Sprintf("complete -c %s -n %s -a %s", quoted, condition, fishQuote(sub.Name))
+			if sub.Help != "" {
+				line += " -d " + fishQuote(strings.Join(strings.Fields(sub.Help), " "))
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteAll quotes every word and joins them with sep.
+func quoteAll(words []string, quote func(string) string, sep string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = quote(word)
+	}
+	return strings.Join(quoted, sep)
+}
@@ -0,0 +1,129 @@
+package settingo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newCompletionTree() *Settings {
+	root := New()
+	root.name = "tool"
+	root.SetBool("verbose", false, "Verbose output")
+	root.Set("level", "info", "Log level")
+	root.SetChoices("level", "debug", "info")
+	root.SetAlias("level", "l")
+	root.MarkPersistent("verbose")
+	serve := root.AddCommand(&Command{Name: "serve", Help: "Start the server"})
+	serve.Settings.Set("cert", "", "TLS certificate")
+	serve.Settings.SetFileCompletion("cert", "pem", "crt")
+	serve.Settings.Set("root", "", "Document root")
+	serve.Settings.SetDirCompletion("root")
+	return root
+}
+
+func TestCompletionBash(t *testing.T) {
+	var out bytes.Buffer
+	if err := newCompletionTree().GenerateCompletion(&out, "bash"); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"_tool_completion() {",
+		"'serve') cmd=\"$next\" ;;",
+		"'-level'|'--level'|'-l'|'--l')\n\t\t\tCOMPREPLY=($(compgen -W 'debug info' -- \"$cur\"))",
+		"compgen -W '-level -l -verbose -no-verbose serve' -- \"$cur\"",
+		"for ext in 'pem' 'crt'; do",
+		"'-root'|'--root')\n\t\t\tcompopt -o filenames\n\t\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))",
+		"compgen -W '-cert -root -verbose -no-verbose' -- \"$cur\"",
+		"complete -F _tool_completion 'tool'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Error(got, " does not contain ", want)
+		}
+	}
+}
+
+func TestCompletionZsh(t *testing.T) {
+	var out bytes.Buffer
+	if err := newCompletionTree().GenerateCompletion(&out, "zsh"); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"#compdef tool",
+		"compadd -- 'debug' 'info'",
+		"_files -g '*.(pem|crt)'",
+		"_files -/",
+		"'serve:Start the server'",
+		"compdef _tool 'tool'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Error(got, " does not contain ", want)
+		}
+	}
+}
+
+func TestCompletionFish(t *testing.T) {
+	var out bytes.Buffer
+	if err := newCompletionTree().GenerateCompletion(&out, "fish"); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"if contains -- $next 'serve'",
+		`complete -c 'tool' -n '__tool_command \'\'' -o 'level' -o 'l' -x -a 'debug info' -d 'Log level'`,
+		`complete -c 'tool' -n '__tool_command \'\'' -a 'serve' -d 'Start the server'`,
+		`-o 'cert' -x -a '(__fish_complete_suffix .pem; __fish_complete_suffix .crt)'`,
+		`complete -c 'tool' -n '__tool_command \'serve\'' -o 'verbose' -d 'Verbose output'`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Error(got, " does not contain ", want)
+		}
+	}
+}
+
+func TestCompletionTags(t *testing.T) {
+	type Config struct {
+		Level  string `settingo:"Log level" validate:"oneof=debug info"`
+		Config string `settingo:"Config file" complete:"file=yaml yml"`
+		Data   string `settingo:"Data directory" complete:"dir"`
+	}
+	s := New()
+	s.LoadStruct(&Config{})
+	for key, want := range map[string]completion{
+		"level":  {values: []string{"debug", "info"}},
+		"config": {files: true, exts: []string{"yaml", "yml"}},
+		"data":   {dirs: true},
+	} {
+		got := s.completions[key]
+		if strings.Join(got.values, " ") != strings.Join(want.values, " ") || got.files != want.files ||
+			got.dirs != want.dirs || strings.Join(got.exts, " ") != strings.Join(want.exts, " ") {
+			t.Error(key, got, " != ", want)
+		}
+	}
+
+	type Invalid struct {
+		Name string `settingo:"Name" complete:"host"`
+	}
+	s = New()
+	s.LoadStruct(&Invalid{})
+	if err := s.takeErrors(); err == nil {
+		t.Error("unknown completion accepted")
+	}
+}
+
+func TestCompletionChoicesValidate(t *testing.T) {
+	s := New()
+	s.Set("level", "info", "Log level")
+	s.SetChoices("level", "debug", "info")
+	if err := s.ParseArgs([]string{"-level", "trace"}); err == nil {
+		t.Error("trace accepted as level")
+	}
+}
+
+func TestCompletionUnknownShell(t *testing.T) {
+	if err := New().GenerateCompletion(&bytes.Buffer{}, "pwsh"); err == nil {
+		t.Error("pwsh accepted")
+	}
+}
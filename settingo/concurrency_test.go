@@ -0,0 +1,81 @@
+package settingo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentAccess is meant to be run with -race.
+func TestConcurrentAccess(t *testing.T) {
+	os.Setenv("RACEPORT", "9000")
+	defer os.Unsetenv("RACEPORT")
+
+	s := New()
+	s.SetInt("RACEPORT", 8080, "port")
+	s.SetString("RACENAME", "name", "name")
+	s.SetSlice("RACEHOSTS", []string{"a"}, "hosts", ",")
+	s.SetMap("RACEHEADERS", map[string][]string{}, "headers")
+	s.SetBool("RACEDEBUG", false, "debug")
+	s.OnChange("RACEPORT", func(old, new interface{}) {
+		s.GetInt("RACEPORT")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.Get("RACENAME")
+				s.GetInt("RACEPORT")
+				s.GetBool("RACEDEBUG")
+				s.GetSlice("RACEHOSTS")
+				s.GetMap("RACEHEADERS")
+				s.Source("RACEPORT")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.SetString(fmt.Sprintf("RACELAZY%d_%d", i, j), "lazy", "registered late")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := s.ParseArgs([]string{"-racename", "parsed"}); err != nil {
+					t.Error(err)
+				}
+				if err := s.Reload(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				var buf bytes.Buffer
+				if err := s.Dump(&buf, FormatJSON); err != nil {
+					t.Error(err)
+				}
+				type config struct {
+					RaceName string
+					RacePort int
+				}
+				cfg := &config{}
+				s.UpdateStruct(cfg)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.GetInt("RACEPORT") != 9000 || s.Get("RACENAME") != "parsed" {
+		t.Error(s.GetInt("RACEPORT"), s.Get("RACENAME"))
+	}
+	if s.Get("RACELAZY3_49") != "lazy" {
+		t.Error("late registration lost", s.Get("RACELAZY3_49"))
+	}
+}
@@ -0,0 +1,197 @@
+package settingo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dotenvValue is a variable read from a dotenv file, with the place it was defined.
+type dotenvValue struct {
+	value string
+	path  string
+	line  int
+}
+
+// LoadDotEnv reads variables from dotenv files for use by HandleOSInput.
+//
+// The variables are kept in the Settings and the process environment is not
+// modified. A variable that is set in the process environment takes precedence
+// over the same variable in a dotenv file, and later files override earlier ones.
+// Without paths ".env" is read.
+//
+// The files support comments, an optional "export " prefix, single quoted
+// literal values, double quoted values with escapes such as \n, values spanning
+// several lines within quotes, and ${VAR}, $VAR and ${VAR:-default} expansion.
+func (s *Settings) LoadDotEnv(paths ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	s.dotenvPaths = append(s.dotenvPaths, paths...)
+	return s.readDotEnv(paths)
+}
+
+func (s *Settings) readDotEnv(paths []string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := s.parseDotEnv(path, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupEnv looks up an environment variable in the process environment,
+// falling back to the variables loaded by LoadDotEnv.
+func (s *Settings) lookupEnv(name string) (string, bool) {
+	if val, found := os.LookupEnv(name); found {
+		return val, true
+	}
+	if val, found := s.dotenv[name]; found {
+		return val.value, true
+	}
+	return "", false
+}
+
+func (s *Settings) parseDotEnv(path, data string) error {
+	if s.dotenv == nil {
+		s.dotenv = make(map[string]dotenvValue)
+	}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		eq := strings.Index(line, "=")
+		if eq < 1 {
+			return fmt.Errorf("settingo: %s:%d: expected KEY=value", path, lineNumber)
+		}
+		name := strings.TrimSpace(line[:eq])
+		if strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("settingo: %s:%d: invalid variable name %q", path, lineNumber, name)
+		}
+		rest := strings.TrimLeft(line[eq+1:], " \t")
+
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			quote := rest[0]
+			raw := rest[1:]
+			end := closingQuote(raw, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+				end = closingQuote(raw, quote)
+			}
+			if end < 0 {
+				return fmt.Errorf("settingo: %s:%d: unterminated quoted value", path, lineNumber)
+			}
+			raw = raw[:end]
+			if quote == '\'' {
+				value = raw
+			} else {
+				value = s.expandDotEnv(unescapeDotEnv(raw))
+			}
+		} else {
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			value = s.expandDotEnv(strings.TrimSpace(rest))
+		}
+		s.dotenv[name] = dotenvValue{value: value, path: path, line: lineNumber}
+	}
+	return nil
+}
+
+// closingQuote returns the index of the unescaped closing quote in s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotEnv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '$':
+			// Keep the escape so expansion leaves the dollar sign alone.
+			b.WriteString(`\$`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// expandDotEnv replaces ${VAR}, ${VAR:-default} and $VAR with their values.
+func (s *Settings) expandDotEnv(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], `\$`):
+			b.WriteByte('$')
+			i++
+		case strings.HasPrefix(value[i:], "${"):
+			end := strings.Index(value[i:], "}")
+			if end < 0 {
+				b.WriteString(value[i:])
+				return b.String()
+			}
+			name, fallback := value[i+2:i+end], ""
+			if sep := strings.Index(name, ":-"); sep >= 0 {
+				name, fallback = name[:sep], name[sep+2:]
+			}
+			if val, found := s.lookupEnv(name); found && val != "" {
+				b.WriteString(val)
+			} else {
+				b.WriteString(fallback)
+			}
+			i += end
+		case value[i] == '$':
+			end := i + 1
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			if end == i+1 {
+				b.WriteByte('$')
+				continue
+			}
+			val, _ := s.lookupEnv(value[i+1 : end])
+			b.WriteString(val)
+			i = end - 1
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+func isEnvNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,95 @@
+package settingo
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadDotEnv(t *testing.T) {
+	os.Setenv("DOTENVOVERRIDE", "from process")
+	os.Setenv("DOTENVHOME", "/home/settingo")
+	defer os.Unsetenv("DOTENVOVERRIDE")
+	defer os.Unsetenv("DOTENVHOME")
+
+	path := writeTestFile(t, ".env", `# comment
+DOTENVPLAIN=plain value # trailing comment
+export DOTENVEXPORTED=exported
+DOTENVSINGLE='literal ${DOTENVPLAIN} \n'
+DOTENVDOUBLE="line1\nline2 \"quoted\""
+DOTENVMULTI="first
+second"
+DOTENVEXPAND=${DOTENVHOME}/data:$DOTENVEXPORTED
+DOTENVFALLBACK=${DOTENVMISSING:-fallback}
+DOTENVESCAPED="\$DOTENVHOME"
+DOTENVOVERRIDE=from file
+DOTENVEMPTY=
+`)
+
+	s := New()
+	if err := s.LoadDotEnv(path); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"DOTENVPLAIN":    "plain value",
+		"DOTENVEXPORTED": "exported",
+		"DOTENVSINGLE":   `literal ${DOTENVPLAIN} \n`,
+		"DOTENVDOUBLE":   "line1\nline2 \"quoted\"",
+		"DOTENVMULTI":    "first\nsecond",
+		"DOTENVEXPAND":   "/home/settingo/data:exported",
+		"DOTENVFALLBACK": "fallback",
+		"DOTENVESCAPED":  "$DOTENVHOME",
+		"DOTENVOVERRIDE": "from process",
+		"DOTENVEMPTY":    "",
+	}
+	for name, want := range expected {
+		got, found := s.lookupEnv(name)
+		if !found || got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, found := os.LookupEnv("DOTENVPLAIN"); found {
+		t.Error("LoadDotEnv modified the process environment")
+	}
+	if s.dotenv["DOTENVMULTI"].line != 6 {
+		t.Error("unexpected line", s.dotenv["DOTENVMULTI"].line)
+	}
+}
+
+func TestLoadDotEnvFeedsParse(t *testing.T) {
+	base := writeTestFile(t, "base.env", "DOTENVPORT=9000\nDOTENVHOSTS=a,b\n")
+	local := writeTestFile(t, "local.env", "DOTENVPORT=9001\n")
+
+	s := New()
+	s.SetInt("DOTENVPORT", 8080, "port")
+	s.SetSlice("DOTENVHOSTS", []string{}, "hosts", ",")
+	if err := s.LoadDotEnv(base, local); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.GetInt("DOTENVPORT") != 9001 {
+		t.Error(s.GetInt("DOTENVPORT"), " != ", 9001)
+	}
+	if !reflect.DeepEqual(s.GetSlice("DOTENVHOSTS"), []string{"a", "b"}) {
+		t.Error(s.GetSlice("DOTENVHOSTS"))
+	}
+}
+
+func TestLoadDotEnvErrors(t *testing.T) {
+	testcases := []string{
+		"NOVALUE\n",
+		"=value\n",
+		"BAD NAME=value\n",
+		"UNTERMINATED=\"value\n",
+	}
+	for _, content := range testcases {
+		if err := New().LoadDotEnv(writeTestFile(t, ".env", content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+	if err := New().LoadDotEnv(writeTestFile(t, ".env", "") + ".missing"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
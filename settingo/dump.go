@@ -0,0 +1,145 @@
+package settingo
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects the output format of Dump.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// redacted replaces the value of secret settings in Dump.
+const redacted = "[REDACTED]"
+
+// SetSecret registers a string setting whose value is redacted by Dump.
+func (s *Settings) SetSecret(flagName, defaultVar, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[s.set(flagName, defaultVar, message)] = true
+}
+
+// MarkSecret marks already registered settings as secret, so that Dump redacts them.
+func (s *Settings) MarkSecret(flagNames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, flagName := range flagNames {
+		s.secrets[s.key(flagName)] = true
+	}
+}
+
+// dumpEntry is a single setting as written by Dump.
+type dumpEntry struct {
+	Name    string      `json:"name" yaml:"name"`
+	Type    string      `json:"type" yaml:"type"`
+	Value   interface{} `json:"value" yaml:"value"`
+	Default interface{} `json:"default" yaml:"default"`
+	Source  string      `json:"source" yaml:"source"`
+	Help    string      `json:"help" yaml:"help"`
+	Secret  bool        `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+func (s *Settings) dumpEntries() []dumpEntry {
+	entries := []dumpEntry{}
+	for _, key := range s.names() {
+		val, _ := s.value(key)
+		entry := dumpEntry{
+			Name:    key,
+			Type:    s.typeName(key),
+			Value:   s.dumpValue(key, val),
+			Default: s.dumpValue(key, s.defaults[key]),
+			Source:  s.origins[key].String(),
+			Help:    s.msg[key],
+			Secret:  s.secrets[key],
+		}
+		if entry.Secret {
+			entry.Value = redact(s.format(key, entry.Value))
+			entry.Default = redact(s.format(key, entry.Default))
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// dumpValue returns val as JSON and YAML should show it: durations, times and
+// custom field types by their string form, as in the text format, everything
+// else with its own type.
+func (s *Settings) dumpValue(key string, val interface{}) interface{} {
+	switch val.(type) {
+	case time.Duration, time.Time, flag.Value:
+		return s.format(key, val)
+	}
+	return val
+}
+
+// redactError replaces the secret values in the message of err, quoted or not.
+// The original error is not kept, as unwrapping it would reveal them again.
+func redactError(err error, values ...string) error {
+	msg := err.Error()
+	for _, val := range values {
+		if val == "" {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, strconv.Quote(val), strconv.Quote(redacted))
+		msg = strings.ReplaceAll(msg, val, redacted)
+	}
+	return errors.New(msg)
+}
+
+// redact hides a secret value, but keeps showing that it is empty.
+func redact(val string) string {
+	if val == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Dump writes every registered setting with its type, effective value, default,
+// source and help message to w. Secret settings are redacted.
+//
+// The text format is an aligned table meant for logs, the JSON and YAML
+// formats contain a list of settings with typed values. Durations, times and
+// custom field types are written in their string form in every format.
+func (s *Settings) Dump(w io.Writer, format Format) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.dumpEntries()
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(entries); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatText, "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tTYPE\tVALUE\tDEFAULT\tSOURCE\tHELP")
+		for _, entry := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				entry.Name, entry.Type,
+				s.format(entry.Name, entry.Value), s.format(entry.Name, entry.Default),
+				entry.Source, entry.Help)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("settingo: unknown dump format %q", format)
+}
@@ -0,0 +1,195 @@
+package settingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func newDumpSettings(t *testing.T) *Settings {
+	t.Helper()
+	os.Setenv("DUMPPORT", "9000")
+	t.Cleanup(func() { os.Unsetenv("DUMPPORT") })
+
+	s := New()
+	s.SetInt("DUMPPORT", 8080, "port to listen on")
+	s.SetSlice("DUMPHOSTS", []string{"a", "b"}, "hosts", ";")
+	s.SetSecret("DUMPPASSWORD", "hunter2", "database password")
+	s.SetSecret("DUMPTOKEN", "", "api token")
+	if err := s.ParseArgs([]string{"-dumppassword", "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDumpText(t *testing.T) {
+	s := newDumpSettings(t)
+	var buf bytes.Buffer
+	if err := s.Dump(&buf, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cret") {
+		t.Error("secret leaked in dump:\n", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 settings, got:\n%s", out)
+	}
+	if strings.Join(strings.Fields(lines[3]), " ") != "dumpport int 9000 8080 env DUMPPORT port to listen on" {
+		t.Errorf("unexpected line %q", lines[3])
+	}
+	if !strings.Contains(lines[1], "a;b") {
+		t.Errorf("slice not formatted with its separator: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], redacted+"  "+redacted+"  flag -dumppassword") {
+		t.Errorf("secret not redacted: %q", lines[2])
+	}
+}
+
+func TestDumpJSONAndYAML(t *testing.T) {
+	s := newDumpSettings(t)
+
+	var buf bytes.Buffer
+	if err := s.Dump(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := s.Dump(&buf, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML []map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+
+	for format, entries := range map[string][]map[string]interface{}{"json": fromJSON, "yaml": fromYAML} {
+		if len(entries) != 4 {
+			t.Fatalf("%s: expected 4 settings, got %v", format, entries)
+		}
+		password, port, token := entries[1], entries[2], entries[3]
+		if password["value"] != redacted || password["default"] != redacted || password["secret"] != true {
+			t.Errorf("%s: secret not redacted: %v", format, password)
+		}
+		if token["value"] != "" {
+			t.Errorf("%s: empty secret should stay empty: %v", format, token)
+		}
+		if port["source"] != "env DUMPPORT" || port["type"] != "int" || port["help"] != "port to listen on" {
+			t.Errorf("%s: unexpected entry %v", format, port)
+		}
+	}
+	if fromJSON[2]["value"] != float64(9000) || fromYAML[2]["value"] != 9000 {
+		t.Error("expected typed values", fromJSON[2]["value"], fromYAML[2]["value"])
+	}
+
+	if err := s.Dump(&buf, Format("xml")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDumpStructSecretTag(t *testing.T) {
+	type config struct {
+		DumpAPIKey string `settingo:"api key" secret:"true"`
+	}
+	s := New()
+	s.LoadStruct(&config{DumpAPIKey: "abc"})
+	var buf bytes.Buffer
+	if err := s.Dump(&buf, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "abc") {
+		t.Error("secret struct field leaked:\n", buf.String())
+	}
+}
+
+// dumpStructured returns the entries of the JSON and YAML dumps of s.
+func dumpStructured(t *testing.T, s *Settings) map[string][]map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := s.Dump(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := s.Dump(&buf, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML []map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]map[string]interface{}{"json": fromJSON, "yaml": fromYAML}
+}
+
+func TestDumpValueField(t *testing.T) {
+	type config struct {
+		Addr net.IP `settingo:"listen address"`
+	}
+	s := New()
+	s.LoadStruct(&config{Addr: net.ParseIP("127.0.0.1")})
+	if err := s.ParseArgs([]string{"-addr", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	for format, entries := range dumpStructured(t, s) {
+		if entries[0]["value"] != "10.0.0.1" || entries[0]["default"] != "127.0.0.1" {
+			t.Errorf("%s: %v != 10.0.0.1 and 127.0.0.1", format, entries[0])
+		}
+	}
+}
+
+func TestDumpDurationAndTime(t *testing.T) {
+	s := New()
+	s.SetDuration("timeout", time.Second, "timeout")
+	s.SetTime("since", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "since")
+	for format, entries := range dumpStructured(t, s) {
+		since, timeout := entries[0], entries[1]
+		if timeout["value"] != "1s" || timeout["default"] != "1s" {
+			t.Errorf("%s: %v != 1s", format, timeout)
+		}
+		if since["value"] != "2024-01-02T03:04:05Z" {
+			t.Errorf("%s: %v != 2024-01-02T03:04:05Z", format, since)
+		}
+	}
+}
+
+func TestSecretConversionErrorRedacted(t *testing.T) {
+	type config struct {
+		DSN *url.URL `settingo:"database" secret:"true"`
+	}
+	os.Setenv("DSN", "postgres://app:hunter2%zz@db")
+	defer os.Unsetenv("DSN")
+
+	s := New()
+	s.LoadStruct(&config{})
+	s.SetInt("secretpin", 0, "pin")
+	s.MarkSecret("secretpin")
+	err := s.ParseArgs([]string{"-secretpin", "12ab"})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 2 {
+		t.Fatal("expected 2 errors, got", err)
+	}
+	for _, parseErr := range parseErrs {
+		if parseErr.Value != redacted {
+			t.Error(parseErr.Value, " != ", redacted)
+		}
+	}
+	if strings.Contains(err.Error(), "hunter2") || strings.Contains(err.Error(), "12ab") {
+		t.Error(err, " contains a secret")
+	}
+}
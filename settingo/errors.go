@@ -0,0 +1,79 @@
+package settingo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError describes a single setting whose input could not be converted.
+//
+// Name is the registered setting name, Source the input it was read from
+// and Value the raw string as it was found in that input.
+type ParseError struct {
+	Name   string
+	Source SourceKind
+	Value  string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s %s: invalid value %q: %v", e.Source, e.Name, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors collects every ParseError found during a single parse.
+//
+// It is returned by ParseE and ParseToE so that all misconfigured settings
+// can be reported at once instead of failing on the first one.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	if len(e) == 1 {
+		lines = append(lines, "settingo: 1 setting could not be parsed:")
+	} else {
+		lines = append(lines, fmt.Sprintf("settingo: %d settings could not be parsed:", len(e)))
+	}
+	for _, err := range e {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// discardedItems is the error of a map value with items that are not key:values pairs.
+type discardedItems []string
+
+func (d discardedItems) Error() string {
+	return fmt.Sprintf("unable to parse map items %q", []string(d))
+}
+
+// applied reports whether the value that failed with err was stored anyway,
+// as a map is with the items that could be parsed.
+func applied(err error) bool {
+	var discarded discardedItems
+	return errors.As(err, &discarded)
+}
+
+// addError records that value, read from source, was rejected for the setting name.
+// The value of a secret setting is redacted, in Value and in the message of err.
+func (s *Settings) addError(name string, source SourceKind, value string, err error) {
+	if s.secrets[s.key(name)] {
+		err = redactError(err, value)
+		value = redact(value)
+	}
+	s.errs = append(s.errs, &ParseError{Name: name, Source: source, Value: value, Err: err})
+}
+
+// takeErrors returns the errors collected so far and resets the collection.
+func (s *Settings) takeErrors() error {
+	errs := s.errs
+	s.errs = nil
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseECollectsEnvErrors(t *testing.T) {
+	s := New()
+	s.SetInt("ERRPORT", 8080, "port")
+	s.SetMap("ERRHEADERS", map[string][]string{}, "headers")
+	s.SetString("ERRNAME", "default", "name")
+
+	os.Setenv("ERRPORT", "abc")
+	os.Setenv("ERRHEADERS", "foo:bar;broken")
+	os.Setenv("ERRNAME", "other")
+	defer os.Unsetenv("ERRPORT")
+	defer os.Unsetenv("ERRHEADERS")
+	defer os.Unsetenv("ERRNAME")
+
+	err := s.ParseArgs(nil)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(parseErrs), err)
+	}
+	if parseErrs[0].Name != "errheaders" || parseErrs[0].Source != SourceEnv || parseErrs[0].Value != "foo:bar;broken" {
+		t.Error("unexpected error", parseErrs[0])
+	}
+	if parseErrs[1].Name != "errport" || parseErrs[1].Source != SourceEnv || parseErrs[1].Value != "abc" {
+		t.Error("unexpected error", parseErrs[1])
+	}
+	if !strings.Contains(err.Error(), "2 settings could not be parsed") {
+		t.Error("unexpected message", err.Error())
+	}
+
+	if s.GetInt("ERRPORT") != 8080 {
+		t.Error(s.GetInt("ERRPORT"), " != ", 8080)
+	}
+	if s.Get("ERRNAME") != "other" {
+		t.Error(s.Get("ERRNAME"), " != ", "other")
+	}
+
+	if err := s.ParseArgs(nil); err == nil {
+		t.Error("expected errors on second parse")
+	}
+	os.Setenv("ERRPORT", "8081")
+	os.Setenv("ERRHEADERS", "foo:bar")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestParseToEReportsStructErrors(t *testing.T) {
+	type config struct {
+		ErrStructName string
+		ErrStructRate complex64
+		hidden        int
+	}
+	s := New()
+	cfg := &config{ErrStructName: "name"}
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	err := s.ParseToE(cfg)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 1 || parseErrs[0].Name != "ERRSTRUCTRATE" || parseErrs[0].Source != SourceStruct {
+		t.Error("unexpected errors", err)
+	}
+	if cfg.ErrStructName != "name" {
+		t.Error(cfg.ErrStructName, " != ", "name")
+	}
+
+	if err := s.ParseToE(42); err == nil {
+		t.Error("expected an error for a non struct")
+	}
+}
+
+func TestParseAppliesValidMapItems(t *testing.T) {
+	s := New()
+	s.SetMap("DISCARDHEADERS", map[string][]string{"a": {"0"}}, "headers")
+	os.Setenv("DISCARDHEADERS", "a:1;broken")
+	defer os.Unsetenv("DISCARDHEADERS")
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	out := captureOutput(t, &os.Stdout, s.Parse)
+	if out != "" {
+		t.Error(out, " != ", "")
+	}
+	if s.GetMap("DISCARDHEADERS")["a"][0] != "1" {
+		t.Error(s.GetMap("DISCARDHEADERS"), " != ", "map[a:[1]]")
+	}
+	if s.Source("DISCARDHEADERS").Kind != SourceEnv {
+		t.Error(s.Source("DISCARDHEADERS").Kind, " != ", SourceEnv)
+	}
+
+	err := s.ParseE()
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 || !strings.Contains(err.Error(), `"broken"`) {
+		t.Error(err, " does not report the discarded item")
+	}
+}
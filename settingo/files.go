@@ -0,0 +1,270 @@
+package settingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileDecoders maps a file extension to the decoder for that configuration format.
+// Decoders return the decoded values and, when the format reports them, the line
+// of every key, with nested keys joined by a dot.
+var fileDecoders = map[string]func([]byte) (map[string]interface{}, map[string]int, error){
+	".json": decodeJSON,
+	".yaml": decodeYAML,
+	".yml":  decodeYAML,
+	".toml": decodeTOML,
+}
+
+func decodeJSON(data []byte) (map[string]interface{}, map[string]int, error) {
+	parsed := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&parsed)
+	return parsed, nil, err
+}
+
+func decodeYAML(data []byte) (map[string]interface{}, map[string]int, error) {
+	parsed := make(map[string]interface{})
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, err
+	}
+	if len(document.Content) == 0 {
+		return parsed, nil, nil
+	}
+	if err := document.Content[0].Decode(&parsed); err != nil {
+		return nil, nil, err
+	}
+	lines := make(map[string]int)
+	yamlLines("", document.Content[0], lines)
+	return parsed, lines, nil
+}
+
+// yamlLines records the line of every key in a YAML mapping.
+func yamlLines(prefix string, node *yaml.Node, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := prefix + node.Content[i].Value
+		lines[name] = node.Content[i].Line
+		yamlLines(name+".", node.Content[i+1], lines)
+	}
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, map[string]int, error) {
+	parsed := make(map[string]interface{})
+	err := toml.Unmarshal(data, &parsed)
+	return parsed, nil, err
+}
+
+// SetConfigFile registers a string setting holding the path of a configuration file.
+//
+// During Parse the path is resolved from the environment and the command line
+// first, then the file is loaded before the environment and the command line
+// are applied, which gives the precedence: defaults, file, environment, flags.
+// A missing file is only an error when the path was given explicitly.
+func (s *Settings) SetConfigFile(flagName, defaultPath, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configKey = s.set(flagName, defaultPath, message)
+	s.completions[s.configKey] = completion{files: true}
+}
+
+// LoadFile reads the configuration file at path into the registered settings.
+//
+// The format is chosen by the extension: .json, .yaml, .yml or .toml.
+// Keys are matched against setting names, nested tables are matched with their
+// names joined by a dot, e.g. "db.host". Keys that match no setting are ignored.
+// Values that cannot be converted are returned as a ParseErrors.
+func (s *Settings) LoadFile(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = append(s.files, path)
+	if err := s.loadFile(path); err != nil {
+		s.errs = nil
+		return err
+	}
+	return s.takeErrors()
+}
+
+func (s *Settings) loadFile(path string) error {
+	decode, found := fileDecoders[strings.ToLower(filepath.Ext(path))]
+	if !found {
+		return fmt.Errorf("settingo: unsupported configuration file format %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parsed, lines, err := decode(data)
+	if err != nil {
+		return fmt.Errorf("settingo: %s: %w", path, err)
+	}
+	s.applyFileValues(path, "", parsed, lines)
+	return nil
+}
+
+// handleFileInput loads the file named by the config file setting, if any.
+func (s *Settings) handleFileInput(args []string) {
+	if s.configKey == "" {
+		return
+	}
+	path, source := s.configPath(args)
+	s.configFile = path
+	if path == "" {
+		return
+	}
+	err := s.loadFile(path)
+	if errors.Is(err, os.ErrNotExist) && source == SourceDefault {
+		return
+	}
+	if err != nil {
+		s.addError(s.configKey, source, path, err)
+	}
+}
+
+// configPath resolves the config file setting before the other settings are parsed.
+func (s *Settings) configPath(args []string) (string, SourceKind) {
+	path, source := s.VarString[s.configKey], SourceDefault
+	if s.reads(SourceEnv) {
+		if val, found := s.lookupEnv(s.envName(s.configKey)); found {
+			path, source = val, SourceEnv
+		}
+	}
+	if !s.reads(SourceFlag) {
+		return path, source
+	}
+	if flagPath, found := s.configFlag(args); found {
+		path, source = flagPath, SourceFlag
+	}
+	return path, source
+}
+
+// configFlag finds the config file flag, or one of its aliases, in args with
+// a first pass through a flag set that knows every setting. Invalid input is
+// ignored here and reported by the real pass.
+func (s *Settings) configFlag(args []string) (string, bool) {
+	fs := flag.NewFlagSet(s.programName(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	s.defineFlags(fs, nil)
+	s.registerAliases(fs)
+	for _, parent := range s.ancestors() {
+		parent.mu.RLock()
+		parent.defineFlags(fs, parent.persistent)
+		parent.registerAliases(fs)
+		parent.mu.RUnlock()
+	}
+	fs.Parse(s.expandShortFlags(fs, args))
+
+	path, found := "", false
+	fs.Visit(func(f *flag.Flag) {
+		if val, ok := f.Value.(*flagValue); ok && val.owner == s.owner() && s.canonical(f.Name) == s.configKey {
+			path, found = val.value, true
+		}
+	})
+	return path, found
+}
+
+func (s *Settings) applyFileValues(path, prefix string, values map[string]interface{}, lines map[string]int) {
+	for name, val := range values {
+		key := s.key(prefix + name)
+		if nested, ok := val.(map[string]interface{}); ok {
+			if _, found := s.VarMap[key]; !found {
+				s.applyFileValues(path, prefix+name+".", nested, lines)
+				continue
+			}
+		}
+		if _, found := s.msg[key]; !found {
+			continue
+		}
+		if err := s.setFromFileValue(key, val); err != nil {
+			s.addError(key, SourceFile, fmt.Sprint(val), err)
+			if !applied(err) {
+				continue
+			}
+		}
+		s.origins[key] = SourceInfo{Kind: SourceFile, File: path, Line: lines[prefix+name]}
+	}
+}
+
+// setFromFileValue stores a decoded file value, keeping lists and tables intact
+// for slice and map settings instead of going through their string notation.
+func (s *Settings) setFromFileValue(key string, val interface{}) error {
+	switch typed := val.(type) {
+	case []interface{}:
+		if _, found := s.VarSlice[key]; !found {
+			return errors.New("unexpected list")
+		}
+		slice, err := fileStrings(typed)
+		if err != nil {
+			return err
+		}
+		s.VarSlice[key] = slice
+		return nil
+	case map[string]interface{}:
+		parsed := make(map[string][]string)
+		for k, v := range typed {
+			values, isList := v.([]interface{})
+			if !isList {
+				values = []interface{}{v}
+			}
+			slice, err := fileStrings(values)
+			if err != nil {
+				return err
+			}
+			parsed[k] = slice
+		}
+		s.VarMap[key] = parsed
+		return nil
+	}
+	raw, err := fileScalar(val)
+	if err != nil {
+		return err
+	}
+	return s.setFromString(key, raw)
+}
+
+func fileStrings(values []interface{}) ([]string, error) {
+	slice := make([]string, len(values))
+	for i, v := range values {
+		raw, err := fileScalar(v)
+		if err != nil {
+			return nil, err
+		}
+		slice[i] = raw
+	}
+	return slice, nil
+}
+
+// fileScalar returns the string notation of a decoded scalar.
+func fileScalar(val interface{}) (string, error) {
+	switch typed := val.(type) {
+	case string:
+		return typed, nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case json.Number:
+		return typed.String(), nil
+	case int, int64, uint64, float64:
+		return fmt.Sprint(typed), nil
+	case time.Time:
+		return typed.Format(time.RFC3339Nano), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected value of type %T", val)
+}
@@ -0,0 +1,142 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newFileSettings() *Settings {
+	s := New()
+	s.SetString("FILEHOST", "localhost", "host")
+	s.SetInt("FILEPORT", 8080, "port")
+	s.SetBool("FILEDEBUG", false, "debug")
+	s.SetSlice("FILETAGS", []string{}, "tags", ",")
+	s.SetMap("FILEHEADERS", map[string][]string{}, "headers")
+	s.SetString("DB.FILEUSER", "root", "database user")
+	return s
+}
+
+func TestLoadFileFormats(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+	}{
+		{"config.json", `{"filehost": "example.com", "fileport": 9000, "filedebug": true,
+			"filetags": ["a", "b,c"], "fileheaders": {"accept": ["json", "xml"], "x-id": "1"},
+			"db": {"fileuser": "admin"}, "unknown": 1}`},
+		{"config.yaml", "filehost: example.com\nfileport: 9000\nfiledebug: true\nfiletags: [a, 'b,c']\n" +
+			"fileheaders:\n  accept: [json, xml]\n  x-id: 1\ndb:\n  fileuser: admin\nunknown: 1\n"},
+		{"config.toml", "filehost = \"example.com\"\nfileport = 9000\nfiledebug = true\nfiletags = [\"a\", \"b,c\"]\n" +
+			"unknown = 1\n[fileheaders]\naccept = [\"json\", \"xml\"]\nx-id = 1\n[db]\nfileuser = \"admin\"\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newFileSettings()
+			if err := s.LoadFile(writeTestFile(t, tc.name, tc.content)); err != nil {
+				t.Fatal(err)
+			}
+			if s.Get("FILEHOST") != "example.com" {
+				t.Error(s.Get("FILEHOST"), " != ", "example.com")
+			}
+			if s.GetInt("FILEPORT") != 9000 {
+				t.Error(s.GetInt("FILEPORT"), " != ", 9000)
+			}
+			if !s.GetBool("FILEDEBUG") {
+				t.Error("expected FILEDEBUG to be true")
+			}
+			if !reflect.DeepEqual(s.GetSlice("FILETAGS"), []string{"a", "b,c"}) {
+				t.Error(s.GetSlice("FILETAGS"))
+			}
+			expectedHeaders := map[string][]string{"accept": {"json", "xml"}, "x-id": {"1"}}
+			if !reflect.DeepEqual(s.GetMap("FILEHEADERS"), expectedHeaders) {
+				t.Error(s.GetMap("FILEHEADERS"), " != ", expectedHeaders)
+			}
+			if s.Get("DB.FILEUSER") != "admin" {
+				t.Error(s.Get("DB.FILEUSER"), " != ", "admin")
+			}
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	s := newFileSettings()
+	err := s.LoadFile(writeTestFile(t, "config.json", `{"fileport": "many", "filehost": ["a"]}`))
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 2 || parseErrs[0].Source != SourceFile {
+		t.Error("expected two file errors, got", err)
+	}
+
+	if err := s.LoadFile(writeTestFile(t, "config.ini", "")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if err := s.LoadFile(writeTestFile(t, "config.yaml", "filehost: [")); err == nil {
+		t.Error("expected an error for malformed yaml")
+	}
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", "filehost: file.example.com\nfileport: 9000\nfiledebug: true\n")
+
+	os.Setenv("FILEPORT", "9001")
+	defer os.Unsetenv("FILEPORT")
+
+	s := newFileSettings()
+	s.SetConfigFile("CONFIG", filepath.Join(t.TempDir(), "missing.yaml"), "configuration file")
+	if err := s.ParseArgs([]string{"-config", path, "-filedebug=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Get("FILEHOST") != "file.example.com" {
+		t.Error(s.Get("FILEHOST"), " != ", "file.example.com")
+	}
+	if s.GetInt("FILEPORT") != 9001 {
+		t.Error(s.GetInt("FILEPORT"), " != ", 9001)
+	}
+	if s.GetBool("FILEDEBUG") {
+		t.Error("expected the flag to override the file")
+	}
+
+	missing := newFileSettings()
+	missing.SetConfigFile("CONFIG", filepath.Join(t.TempDir(), "missing.yaml"), "configuration file")
+	if err := missing.ParseArgs(nil); err != nil {
+		t.Error("a missing default config file should be ignored, got", err)
+	}
+	if err := missing.ParseArgs([]string{"-config=" + filepath.Join(t.TempDir(), "other.yaml")}); err == nil {
+		t.Error("expected an error for a missing explicit config file")
+	}
+}
+
+func TestConfigFileFlagPosition(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", "filehost: file.example.com\n")
+
+	for _, args := range [][]string{
+		{"-fileport", "8080", "-config", path},
+		{"-filedebug", "-config=" + path},
+		{"-c", path},
+	} {
+		s := newFileSettings()
+		s.SetConfigFile("CONFIG", "", "configuration file")
+		s.SetAlias("CONFIG", "c")
+		if err := s.ParseArgs(args); err != nil {
+			t.Fatal(err)
+		}
+		if s.Get("FILEHOST") != "file.example.com" {
+			t.Error(args, s.Get("FILEHOST"), " != ", "file.example.com")
+		}
+		if s.Source("FILEHOST").Kind != SourceFile {
+			t.Error(args, s.Source("FILEHOST").Kind, " != ", SourceFile)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package settingo
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Setting is a typed handle to a registered setting, returned by Register.
+// Reading a setting through its handle cannot misspell its name.
+type Setting[T interface{}] struct {
+	settings *Settings
+	key      string
+}
+
+// Register registers a setting on s and returns a typed handle to it.
+//
+// T must be one of the types settingo stores: string, int, bool, int64,
+// uint64, float64, time.Duration, time.Time, []string or map[string][]string.
+// Register panics for other types, and when name is already registered with
+// another type, as that is a mistake in the program.
+//
+// Example:
+//
+//	port := settingo.Register(&settingo.SETTINGS, "port", 8080, "Port to listen on")
+//	settingo.Parse()
+//	http.ListenAndServe(fmt.Sprintf(":%d", port.Get()), nil)
+func Register[T interface{}](s *Settings, name string, def T, help string) *Setting[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, found := s.value(s.key(name)); found && reflect.TypeOf(existing) != reflect.TypeOf(def) {
+		panic(fmt.Sprintf("settingo: Register[%T](%q) on a setting of type %s", def, name, s.typeName(s.key(name))))
+	}
+	switch typed := interface{}(def).(type) {
+	case string, int, bool, int64, uint64, float64, time.Duration, map[string][]string:
+		s.set(name, typed, help)
+	case []string:
+		s.setSlice(name, typed, help, "")
+	case time.Time:
+		s.setTime(name, typed, help, nil)
+	default:
+		panic(fmt.Sprintf("settingo: Register does not support settings of type %T", def))
+	}
+	return &Setting[T]{settings: s, key: s.key(name)}
+}
+
+// Name returns the key the setting is registered under.
+func (h *Setting[T]) Name() string {
+	return h.key
+}
+
+// Get returns the current value of the setting. It panics when the setting
+// was registered again with another type after Register.
+func (h *Setting[T]) Get() T {
+	h.settings.mu.RLock()
+	defer h.settings.mu.RUnlock()
+	val, _ := h.settings.value(h.key)
+	typed, ok := val.(T)
+	if !ok {
+		panic(fmt.Sprintf("settingo: Setting[%T].Get(%q) on a setting of type %s", typed, h.key, h.settings.typeName(h.key)))
+	}
+	return typed
+}
+
+// Set assigns the setting from the program, its source becomes SourceCode.
+// The next Reload replaces the value with the one read from the inputs.
+func (h *Setting[T]) Set(val T) {
+	h.settings.mu.Lock()
+	defer h.settings.mu.Unlock()
+	h.settings.setValue(h.key, copyValue(val))
+	h.settings.origins[h.key] = SourceInfo{Kind: SourceCode}
+}
+
+// Source returns where the current value of the setting came from.
+func (h *Setting[T]) Source() SourceInfo {
+	h.settings.mu.RLock()
+	defer h.settings.mu.RUnlock()
+	return h.settings.origins[h.key]
+}
+
+// IsSet reports whether the value was given by a file, the environment, the
+// command line or Set, rather than being the registered default.
+func (h *Setting[T]) IsSet() bool {
+	kind := h.Source().Kind
+	return kind != SourceDefault && kind != SourceStruct
+}
@@ -0,0 +1,81 @@
+package settingo
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	s := New()
+	port := Register(s, "genport", 8080, "port")
+	hosts := Register(s, "genhosts", []string{"a"}, "hosts")
+	timeout := Register(s, "gentimeout", 5*time.Second, "timeout")
+	name := Register(s, "genname", "app", "name")
+
+	os.Setenv("GENPORT", "9000")
+	defer os.Unsetenv("GENPORT")
+
+	if err := s.ParseArgs([]string{"-genhosts=b,c", "-gentimeout=1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := port.Get(); got != 9000 {
+		t.Error(got, " != ", 9000)
+	}
+	if got := strings.Join(hosts.Get(), ","); got != "b,c" {
+		t.Error(got, " != ", "b,c")
+	}
+	if got := timeout.Get(); got != time.Minute {
+		t.Error(got, " != ", time.Minute)
+	}
+	if got := port.Source().String(); got != "env GENPORT" {
+		t.Error(got, " != ", "env GENPORT")
+	}
+	if !port.IsSet() || name.IsSet() {
+		t.Error("IsSet should only report settings given by an input")
+	}
+
+	name.Set("other")
+	if got := name.Get(); got != "other" {
+		t.Error(got, " != ", "other")
+	}
+	if got := s.Get("genname"); got != "other" {
+		t.Error(got, " != ", "other")
+	}
+	if !name.IsSet() || name.Source().Kind != SourceCode {
+		t.Error(name.Source(), " != ", SourceCode)
+	}
+	if got := port.Name(); got != "genport" {
+		t.Error(got, " != ", "genport")
+	}
+}
+
+func TestRegisterUnsupportedType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Register to panic for an unsupported type")
+		}
+	}()
+	Register(New(), "genchan", make(chan int), "chan")
+}
+
+func TestRegisterTypeConflict(t *testing.T) {
+	s := New()
+	s.SetString("genconflict", "8080", "port")
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "of type string") {
+			t.Error(msg, " does not name the registered type")
+		}
+	}()
+	Register(s, "genconflict", 1, "port")
+}
+
+func TestRegisterSameType(t *testing.T) {
+	s := New()
+	s.SetInt("gensame", 1, "port")
+	port := Register(s, "gensame", 2, "port")
+	if port.Get() != 2 {
+		t.Error(port.Get(), " != ", 2)
+	}
+}
@@ -0,0 +1,117 @@
+package settingo
+
+import (
+	"fmt"
+	"time"
+)
+
+// lookup returns the value of flagName from vars, the map of one setting type.
+// When getter is not empty and the Settings panic on unknown names, a setting
+// that is missing from vars panics, naming the getter and the actual type.
+func lookup[T interface{}](s *Settings, vars map[string]T, flagName, getter string) (T, bool) {
+	key := s.key(flagName)
+	val, found := vars[key]
+	if !found && getter != "" && s.panicOnUnknown {
+		if typ := s.typeName(key); typ != "" {
+			panic(fmt.Sprintf("settingo: %s(%q) on a setting of type %s", getter, flagName, typ))
+		}
+		panic(fmt.Sprintf("settingo: %s(%q) on an unregistered setting", getter, flagName))
+	}
+	return val, found
+}
+
+// SetPanicOnUnknown makes the Get methods panic when the name is not
+// registered, or is registered with another type, e.g. GetInt on a string
+// setting. Meant for development builds and tests, where such a mistake
+// should not silently return a zero value.
+func (s *Settings) SetPanicOnUnknown(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panicOnUnknown = enabled
+}
+
+// IsRegistered reports whether a setting of that name is registered.
+func (s *Settings) IsRegistered(flagName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.value(s.key(flagName))
+	return found
+}
+
+// Type returns the Go type of a registered setting, e.g. "int" or "[]string",
+// and an empty string for unknown names.
+func (s *Settings) Type(flagName string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.typeName(s.key(flagName))
+}
+
+// Lookup is like Get, but also reports whether a string setting of that name is registered.
+func (s *Settings) Lookup(flagName string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarString, flagName, "")
+}
+
+// LookupInt is like GetInt, but also reports whether a int setting of that name is registered.
+func (s *Settings) LookupInt(flagName string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarInt, flagName, "")
+}
+
+// LookupBool is like GetBool, but also reports whether a bool setting of that name is registered.
+func (s *Settings) LookupBool(flagName string) (bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarBool, flagName, "")
+}
+
+// LookupMap is like GetMap, but also reports whether a map[string][]string setting of that name is registered.
+func (s *Settings) LookupMap(flagName string) (map[string][]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarMap, flagName, "")
+}
+
+// LookupSlice is like GetSlice, but also reports whether a []string setting of that name is registered.
+func (s *Settings) LookupSlice(flagName string) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarSlice, flagName, "")
+}
+
+// LookupFloat is like GetFloat, but also reports whether a float64 setting of that name is registered.
+func (s *Settings) LookupFloat(flagName string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarFloat, flagName, "")
+}
+
+// LookupInt64 is like GetInt64, but also reports whether a int64 setting of that name is registered.
+func (s *Settings) LookupInt64(flagName string) (int64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarInt64, flagName, "")
+}
+
+// LookupUint is like GetUint, but also reports whether a uint64 setting of that name is registered.
+func (s *Settings) LookupUint(flagName string) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarUint, flagName, "")
+}
+
+// LookupDuration is like GetDuration, but also reports whether a time.Duration setting of that name is registered.
+func (s *Settings) LookupDuration(flagName string) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarDuration, flagName, "")
+}
+
+// LookupTime is like GetTime, but also reports whether a time.Time setting of that name is registered.
+func (s *Settings) LookupTime(flagName string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return lookup(s, s.VarTime, flagName, "")
+}
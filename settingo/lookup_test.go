@@ -0,0 +1,83 @@
+package settingo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	s := New()
+	s.Set("lookname", "app", "name")
+	s.SetInt("lookport", 8080, "port")
+	s.SetDuration("looktimeout", time.Second, "timeout")
+
+	if val, ok := s.Lookup("lookname"); !ok || val != "app" {
+		t.Error(val, ok, " != ", "app true")
+	}
+	if val, ok := s.LookupInt("LOOKPORT"); !ok || val != 8080 {
+		t.Error(val, ok, " != ", "8080 true")
+	}
+	if _, ok := s.LookupInt("lookname"); ok {
+		t.Error("LookupInt should not find a string setting")
+	}
+	if _, ok := s.LookupInt("prot"); ok {
+		t.Error("LookupInt should not find an unknown setting")
+	}
+	if !s.IsRegistered("lookport") || s.IsRegistered("prot") {
+		t.Error("IsRegistered does not match the registered settings")
+	}
+	for name, want := range map[string]string{"lookname": "string", "lookport": "int", "looktimeout": "time.Duration", "prot": ""} {
+		if got := s.Type(name); got != want {
+			t.Error(name, ": ", got, " != ", want)
+		}
+	}
+}
+
+func TestTypeOfStructField(t *testing.T) {
+	type config struct {
+		Retries int8
+		Workers int32
+		Port    uint16
+		Ratio   float32
+		Offset  int64
+	}
+	s := New()
+	s.LoadStruct(&config{})
+	for name, want := range map[string]string{"retries": "int8", "workers": "int32", "port": "uint16", "ratio": "float32", "offset": "int64"} {
+		if got := s.Type(name); got != want {
+			t.Error(name, ": ", got, " != ", want)
+		}
+	}
+}
+
+func TestPanicOnUnknown(t *testing.T) {
+	s := New(WithPanicOnUnknown())
+	s.Set("lookname", "app", "name")
+
+	if got := s.Get("lookname"); got != "app" {
+		t.Error(got, " != ", "app")
+	}
+	if _, ok := s.LookupInt("lookname"); ok {
+		t.Error("LookupInt should not find a string setting")
+	}
+
+	expectPanic := func(want string, get func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			msg, _ := recover().(string)
+			if !strings.Contains(msg, want) {
+				t.Error("panic ", msg, " does not contain ", want)
+			}
+		}()
+		get()
+	}
+	expectPanic(`GetInt("lookname") on a setting of type string`, func() { s.GetInt("lookname") })
+	expectPanic(`Get("prot") on an unregistered setting`, func() { s.Get("prot") })
+
+	s.SetPanicOnUnknown(false)
+	if got := s.GetInt("prot"); got != 0 {
+		t.Error(got, " != ", 0)
+	}
+}
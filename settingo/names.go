@@ -0,0 +1,79 @@
+package settingo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper derives the flag and environment variable names of settings.
+//
+// Both methods receive the name a setting was registered with, such as
+// "maxConnections" or, for a nested struct field, "DB.MaxConnections".
+// FlagName must return names that map to themselves, as the flag name is
+// also the key the setting is looked up by.
+type NameMapper interface {
+	FlagName(name string) string
+	EnvName(name string) string
+}
+
+// ConventionalNames maps camelCase and snake_case names to kebab-case flags
+// and SCREAMING_SNAKE_CASE environment variables: MaxConnections becomes
+// -max-connections and MAX_CONNECTIONS.
+var ConventionalNames NameMapper = conventionalNames{}
+
+type conventionalNames struct{}
+
+func (conventionalNames) FlagName(name string) string {
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		segments[i] = strings.ToLower(strings.Join(words(segment), "-"))
+	}
+	return strings.Join(segments, ".")
+}
+
+func (conventionalNames) EnvName(name string) string {
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		segments[i] = strings.ToUpper(strings.Join(words(segment), "_"))
+	}
+	return strings.Join(segments, "_")
+}
+
+// words splits a name into its words at underscores, dashes, spaces and case
+// changes, keeping acronyms together: "HTTPServer" is HTTP, Server. Adjacent
+// acronyms cannot be told apart, "DBURLPrefix" is DBURL, Prefix.
+func words(name string) []string {
+	var result []string
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if i > start {
+				result = append(result, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		previous := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+			result = append(result, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		result = append(result, string(runes[start:]))
+	}
+	return result
+}
+
+// SetNameMapper sets how flag and environment variable names are derived
+// from setting names. Set it before registering settings.
+func (s *Settings) SetNameMapper(mapper NameMapper) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nameMapper = mapper
+}
@@ -0,0 +1,82 @@
+package settingo
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConventionalNames(t *testing.T) {
+	cases := []struct {
+		name, flag, env string
+	}{
+		{"MaxConnections", "max-connections", "MAX_CONNECTIONS"},
+		{"maxConnections", "max-connections", "MAX_CONNECTIONS"},
+		{"max-connections", "max-connections", "MAX_CONNECTIONS"},
+		{"MAX_CONNECTIONS", "max-connections", "MAX_CONNECTIONS"},
+		{"DBURL", "dburl", "DBURL"},
+		{"DBURLPrefix", "dburl-prefix", "DBURL_PREFIX"},
+		{"HTTPServer", "http-server", "HTTP_SERVER"},
+		{"Port2Listen", "port2-listen", "PORT2_LISTEN"},
+		{"DB.MaxIdle", "db.max-idle", "DB_MAX_IDLE"},
+	}
+	for _, c := range cases {
+		if got := ConventionalNames.FlagName(c.name); got != c.flag {
+			t.Error(c.name, ": ", got, " != ", c.flag)
+		}
+		if got := ConventionalNames.EnvName(c.name); got != c.env {
+			t.Error(c.name, ": ", got, " != ", c.env)
+		}
+		if got := ConventionalNames.FlagName(c.flag); got != c.flag {
+			t.Error(c.flag, " is not mapped to itself: ", got)
+		}
+	}
+}
+
+func TestNameMapperSettings(t *testing.T) {
+	os.Setenv("MAPAPP_MAX_CONNECTIONS", "200")
+	os.Setenv("MAPAPP_DB_IDLE_TIMEOUT", "30")
+	defer os.Unsetenv("MAPAPP_MAX_CONNECTIONS")
+	defer os.Unsetenv("MAPAPP_DB_IDLE_TIMEOUT")
+
+	type database struct {
+		IdleTimeout int
+		MaxOpen     int
+	}
+	type config struct {
+		MaxConnections int
+		DB             database
+	}
+	cfg := &config{MaxConnections: 100}
+
+	args := os.Args
+	os.Args = []string{"settingo", "--db.max-open=5"}
+	defer func() { os.Args = args }()
+
+	s := New(WithNameMapper(ConventionalNames), WithEnvPrefix("MAPAPP_"))
+	s.SetInt("readTimeout", 10, "read timeout")
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxConnections != 200 {
+		t.Error(cfg.MaxConnections, " != ", 200)
+	}
+	if cfg.DB.IdleTimeout != 30 {
+		t.Error(cfg.DB.IdleTimeout, " != ", 30)
+	}
+	if cfg.DB.MaxOpen != 5 {
+		t.Error(cfg.DB.MaxOpen, " != ", 5)
+	}
+	if got := s.GetInt("max-connections"); got != 200 {
+		t.Error(got, " != ", 200)
+	}
+	if got := s.GetInt("MaxConnections"); got != 200 {
+		t.Error(got, " != ", 200)
+	}
+	if got := s.envName(s.key("readTimeout")); got != "MAPAPP_READ_TIMEOUT" {
+		t.Error(got, " != ", "MAPAPP_READ_TIMEOUT")
+	}
+	if !strings.Contains(s.usage("read-timeout"), "MAPAPP_READ_TIMEOUT") {
+		t.Error("help does not name the env variable:", s.usage("read-timeout"))
+	}
+}
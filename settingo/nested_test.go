@@ -0,0 +1,120 @@
+package settingo
+
+import (
+	"os"
+	"testing"
+)
+
+type NestedBase struct {
+	NestedName string
+}
+
+type nestedInternal struct {
+	NestedInternal int
+}
+
+func TestNestedStructs(t *testing.T) {
+	type database struct {
+		Host string
+		Port int
+	}
+	type server struct {
+		Addr string
+	}
+	type config struct {
+		NestedBase
+		nestedInternal
+		DB     database
+		Server *server
+		Cache  *server
+	}
+	cfg := &config{
+		NestedBase:     NestedBase{NestedName: "app"},
+		nestedInternal: nestedInternal{NestedInternal: 1},
+		DB:             database{Host: "localhost", Port: 5432},
+		Server:         &server{Addr: ":8080"},
+	}
+
+	os.Setenv("DB_HOST", "db.internal")
+	os.Setenv("NESTEDINTERNAL", "2")
+	defer os.Unsetenv("DB_HOST")
+	defer os.Unsetenv("NESTEDINTERNAL")
+
+	args := os.Args
+	os.Args = []string{"settingo", "-db.port=6543", "-server.addr=:9090", "-cache.addr=:6379", "-nestedname=svc"}
+	defer func() { os.Args = args }()
+
+	s := New()
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Host != "db.internal" {
+		t.Error(cfg.DB.Host, " != ", "db.internal")
+	}
+	if cfg.DB.Port != 6543 {
+		t.Error(cfg.DB.Port, " != ", 6543)
+	}
+	if cfg.Server.Addr != ":9090" {
+		t.Error(cfg.Server.Addr, " != ", ":9090")
+	}
+	if cfg.Cache == nil || cfg.Cache.Addr != ":6379" {
+		t.Error(cfg.Cache, " != ", ":6379")
+	}
+	if cfg.NestedName != "svc" {
+		t.Error(cfg.NestedName, " != ", "svc")
+	}
+	if cfg.NestedInternal != 2 {
+		t.Error(cfg.NestedInternal, " != ", 2)
+	}
+	if got := s.Get("DB.HOST"); got != "db.internal" {
+		t.Error(got, " != ", "db.internal")
+	}
+}
+
+func TestNestedStructFromFile(t *testing.T) {
+	type database struct {
+		Host string
+	}
+	type config struct {
+		DB database
+	}
+	path := writeTestFile(t, "nested.yaml", "db:\n  host: file-host\n")
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	cfg := &config{}
+	s := New()
+	s.SetConfigFile("config", path, "configuration file")
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Host != "file-host" {
+		t.Error(cfg.DB.Host, " != ", "file-host")
+	}
+}
+
+func TestNestedStructFlagTag(t *testing.T) {
+	type server struct {
+		Host string `flag:"host"`
+	}
+	type config struct {
+		DB    server
+		Cache server
+	}
+	cfg := &config{}
+
+	s := New()
+	s.LoadStruct(cfg)
+	if err := s.ParseArgs([]string{"-db.host", "db", "-cache.host", "cache"}); err != nil {
+		t.Fatal(err)
+	}
+	s.UpdateStruct(cfg)
+	if cfg.DB.Host != "db" {
+		t.Error(cfg.DB.Host, " != ", "db")
+	}
+	if cfg.Cache.Host != "cache" {
+		t.Error(cfg.Cache.Host, " != ", "cache")
+	}
+}
@@ -0,0 +1,102 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestNumericSettings(t *testing.T) {
+	s := New()
+	s.SetFloat("ratio", 0.5, "ratio")
+	s.SetInt64("max_bytes", 1<<40, "max bytes")
+	s.SetUint("workers", 4, "workers")
+
+	if err := s.ParseArgs([]string{"-ratio=0.25", "-max_bytes=-9000000000", "-workers=18446744073709551615"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetFloat("ratio"); got != 0.25 {
+		t.Error(got, " != ", 0.25)
+	}
+	if got := s.GetInt64("max_bytes"); got != -9000000000 {
+		t.Error(got, " != ", -9000000000)
+	}
+	if got := s.GetUint("workers"); got != 18446744073709551615 {
+		t.Error(got, " != ", uint64(18446744073709551615))
+	}
+}
+
+func TestNumericSettingsInvalidInput(t *testing.T) {
+	s := New()
+	s.SetFloat("ratio", 0.5, "ratio")
+	s.SetUint("workers", 4, "workers")
+
+	os.Setenv("RATIO", "half")
+	os.Setenv("WORKERS", "-1")
+	defer os.Unsetenv("RATIO")
+	defer os.Unsetenv("WORKERS")
+
+	err := s.ParseArgs(nil)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 2 {
+		t.Error(len(parseErrs), " != ", 2)
+	}
+	if got := s.GetFloat("ratio"); got != 0.5 {
+		t.Error(got, " != ", 0.5)
+	}
+	if got := s.GetUint("workers"); got != 4 {
+		t.Error(got, " != ", 4)
+	}
+}
+
+func TestNumericStructFields(t *testing.T) {
+	type config struct {
+		NumRetries int8
+		NumPort    uint16
+		NumSize    int64
+		NumRatio   float32
+		NumWorkers uint
+	}
+	cfg := &config{NumRetries: 3, NumPort: 8080, NumSize: 10, NumRatio: 0.5, NumWorkers: 2}
+
+	os.Setenv("NUMRETRIES", "300")
+	os.Setenv("NUMPORT", "9090")
+	os.Setenv("NUMSIZE", "-42")
+	os.Setenv("NUMRATIO", "0.125")
+	defer os.Unsetenv("NUMRETRIES")
+	defer os.Unsetenv("NUMPORT")
+	defer os.Unsetenv("NUMSIZE")
+	defer os.Unsetenv("NUMRATIO")
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	s := New()
+	err := s.ParseToE(cfg)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 1 || parseErrs[0].Source != SourceEnv {
+		t.Error("unexpected errors", err)
+	}
+	if cfg.NumRetries != 3 {
+		t.Error(cfg.NumRetries, " != ", 3)
+	}
+	if cfg.NumPort != 9090 {
+		t.Error(cfg.NumPort, " != ", 9090)
+	}
+	if cfg.NumSize != -42 {
+		t.Error(cfg.NumSize, " != ", -42)
+	}
+	if cfg.NumRatio != 0.125 {
+		t.Error(cfg.NumRatio, " != ", 0.125)
+	}
+	if cfg.NumWorkers != 2 {
+		t.Error(cfg.NumWorkers, " != ", 2)
+	}
+}
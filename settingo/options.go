@@ -0,0 +1,168 @@
+package settingo
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Option configures a Settings instance created by New.
+type Option func(*Settings)
+
+// New returns an independent, ready to use Settings registry.
+//
+// Each registry has its own settings, its own flag set and no ties to the
+// global SETTINGS, so several components of one binary, or parallel tests,
+// can each parse their own configuration. Without options a registry behaves
+// like SETTINGS: names are case insensitive and both the environment and the
+// command line are read.
+//
+// Example:
+//
+//	s := settingo.New(settingo.WithEnvPrefix("MYAPP_"))
+//	s.SetInt("port", 8080, "Port to listen on")
+//	if err := s.ParseArgs(os.Args[1:]); err != nil {
+//		log.Fatal(err)
+//	}
+func New(opts ...Option) *Settings {
+	s := &Settings{ContextualCasing: true}
+	s.initialize()
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// initialize allocates the locks and the registry maps of a Settings.
+func (s *Settings) initialize() {
+	s.mu = new(sync.RWMutex)
+	s.reloadMu = new(sync.Mutex)
+	s.resetValues()
+	s.msg = make(map[string]string)
+	s.VarSliceSep = make(map[string]string)
+	s.VarTimeLayouts = make(map[string][]string)
+	s.Parsers = make(map[string]func(string) string)
+	s.ParsersInt = make(map[string]func(int) int)
+	s.cmdline = make(map[string]flagInput)
+	s.defaults = make(map[string]interface{})
+	s.secrets = make(map[string]bool)
+	s.rules = make(map[string][]Rule)
+	s.fromStruct = make(map[string]bool)
+	s.callbacks = make(map[string][]func(old, new interface{}))
+	s.fieldTypes = make(map[string]reflect.Type)
+	s.envNames = make(map[string]string)
+	s.registered = make(map[string]string)
+	s.aliases = make(map[string]string)
+	s.persistent = make(map[string]bool)
+	s.groups = make(map[string]string)
+	s.completions = make(map[string]completion)
+}
+
+// resetValues allocates empty maps for the values of the settings and where
+// they came from. Everything else in a Settings describes the registration.
+func (s *Settings) resetValues() {
+	s.VarString = make(map[string]string)
+	s.VarInt = make(map[string]int)
+	s.VarBool = make(map[string]bool)
+	s.VarMap = make(map[string]map[string][]string)
+	s.VarSlice = make(map[string][]string)
+	s.VarFloat = make(map[string]float64)
+	s.VarInt64 = make(map[string]int64)
+	s.VarUint = make(map[string]uint64)
+	s.VarDuration = make(map[string]time.Duration)
+	s.VarTime = make(map[string]time.Time)
+	s.VarValue = make(map[string]flag.Value)
+	s.origins = make(map[string]SourceInfo)
+}
+
+// WithContextualCasing sets whether setting names are case insensitive.
+// When enabled, names are stored in lowercase and looked up in the
+// environment in uppercase.
+func WithContextualCasing(enabled bool) Option {
+	return func(s *Settings) {
+		s.ContextualCasing = enabled
+	}
+}
+
+// WithEnvPrefix prepends prefix to the environment variable of every setting,
+// e.g. with prefix "MYAPP_" the setting "port" is read from MYAPP_PORT.
+func WithEnvPrefix(prefix string) Option {
+	return func(s *Settings) {
+		s.envPrefix = prefix
+	}
+}
+
+// WithEnvFallback reads the environment variable without the env prefix
+// when the prefixed one is not set, e.g. PORT when MYAPP_PORT is missing.
+// It eases moving existing deployments over to a prefix.
+func WithEnvFallback() Option {
+	return func(s *Settings) {
+		s.envFallback = true
+	}
+}
+
+// WithNameMapper derives flag and environment variable names with mapper,
+// e.g. WithNameMapper(ConventionalNames) reads maxConnections from
+// -max-connections and MAX_CONNECTIONS.
+func WithNameMapper(mapper NameMapper) Option {
+	return func(s *Settings) {
+		s.nameMapper = mapper
+	}
+}
+
+// WithStrictEnv rejects unknown environment variables, see SetStrictEnv.
+func WithStrictEnv() Option {
+	return func(s *Settings) {
+		s.strictEnv = true
+	}
+}
+
+// WithPanicOnUnknown makes the Get methods panic on unknown names and
+// type mismatches, see SetPanicOnUnknown.
+func WithPanicOnUnknown() Option {
+	return func(s *Settings) {
+		s.panicOnUnknown = true
+	}
+}
+
+// WithFlagSet registers the flags of the settings on fs instead of on a new
+// flag set per parse. The error handling of fs decides what happens on invalid
+// command line input.
+//
+// The usage function of fs is replaced with the help output of the settings,
+// set fs.Usage after New to use another one.
+func WithFlagSet(fs *flag.FlagSet) Option {
+	return func(s *Settings) {
+		s.flagSet = fs
+		fs.Usage = s.usageFunc(fs)
+	}
+}
+
+// WithSources limits the inputs Parse reads to the given sources,
+// e.g. WithSources(SourceEnv) ignores the command line entirely.
+// Defaults are always applied.
+func WithSources(sources ...SourceKind) Option {
+	return func(s *Settings) {
+		s.sources = make(map[SourceKind]bool)
+		for _, source := range sources {
+			s.sources[source] = true
+		}
+	}
+}
+
+// reads reports whether the source is enabled for this Settings.
+func (s *Settings) reads(source SourceKind) bool {
+	if s.sources == nil {
+		return true
+	}
+	return s.sources[source]
+}
+
+// WithWatchInterval sets how often Watch checks the configuration files for
+// modifications. The default is two seconds.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(s *Settings) {
+		s.watchInterval = interval
+	}
+}
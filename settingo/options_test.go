@@ -0,0 +1,132 @@
+package settingo
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewIndependentRegistries(t *testing.T) {
+	first := New()
+	second := New()
+	first.SetString("NEWNAME", "first", "name")
+	second.SetString("NEWNAME", "second", "name")
+
+	if err := first.ParseArgs([]string{"-newname", "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if first.Get("NEWNAME") != "changed" {
+		t.Error(first.Get("NEWNAME"), " != ", "changed")
+	}
+	if second.Get("NEWNAME") != "second" {
+		t.Error(second.Get("NEWNAME"), " != ", "second")
+	}
+	if _, found := SETTINGS.VarString["newname"]; found {
+		t.Error("New registry leaked into SETTINGS")
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	os.Setenv("OPTAPP_OPTPORT", "9000")
+	os.Setenv("OPTPORT", "9001")
+	defer os.Unsetenv("OPTAPP_OPTPORT")
+	defer os.Unsetenv("OPTPORT")
+
+	prefixed := New(WithEnvPrefix("OPTAPP_"))
+	prefixed.SetInt("OPTPORT", 8080, "port")
+	if err := prefixed.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if prefixed.GetInt("OPTPORT") != 9000 {
+		t.Error(prefixed.GetInt("OPTPORT"), " != ", 9000)
+	}
+
+	flagsOnly := New(WithSources(SourceFlag))
+	flagsOnly.SetInt("OPTPORT", 8080, "port")
+	if err := flagsOnly.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if flagsOnly.GetInt("OPTPORT") != 8080 {
+		t.Error(flagsOnly.GetInt("OPTPORT"), " != ", 8080)
+	}
+
+	envOnly := New(WithSources(SourceEnv))
+	envOnly.SetInt("OPTPORT", 8080, "port")
+	if err := envOnly.ParseArgs([]string{"-optport", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if envOnly.GetInt("OPTPORT") != 9001 {
+		t.Error(envOnly.GetInt("OPTPORT"), " != ", 9001)
+	}
+
+	caseSensitive := New(WithContextualCasing(false))
+	caseSensitive.SetInt("OPTPORT", 8080, "port")
+	if caseSensitive.GetInt("optport") != 0 {
+		t.Error("expected names to be case sensitive")
+	}
+}
+
+func TestEnvPrefixFallback(t *testing.T) {
+	os.Setenv("FALLBACKPORT", "9001")
+	os.Setenv("FBAPP_FALLBACKHOST", "prefixed")
+	os.Setenv("FALLBACKHOST", "unprefixed")
+	defer os.Unsetenv("FALLBACKPORT")
+	defer os.Unsetenv("FBAPP_FALLBACKHOST")
+	defer os.Unsetenv("FALLBACKHOST")
+
+	strict := New(WithEnvPrefix("FBAPP_"))
+	strict.SetInt("fallbackport", 8080, "port")
+	if err := strict.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strict.GetInt("fallbackport"); got != 8080 {
+		t.Error(got, " != ", 8080)
+	}
+
+	s := New(WithEnvPrefix("FBAPP_"), WithEnvFallback())
+	s.SetInt("fallbackport", 8080, "port")
+	s.Set("fallbackhost", "localhost", "host")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetInt("fallbackport"); got != 9001 {
+		t.Error(got, " != ", 9001)
+	}
+	if got := s.Source("fallbackport").Name; got != "FALLBACKPORT" {
+		t.Error(got, " != ", "FALLBACKPORT")
+	}
+	if got := s.Get("fallbackhost"); got != "prefixed" {
+		t.Error(got, " != ", "prefixed")
+	}
+
+	later := New()
+	later.SetEnvPrefix("FBAPP_", false)
+	later.Set("fallbackhost", "localhost", "host")
+	if err := later.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := later.Get("fallbackhost"); got != "prefixed" {
+		t.Error(got, " != ", "prefixed")
+	}
+}
+
+func TestEnvPrefixInHelp(t *testing.T) {
+	fs := flag.NewFlagSet("help", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+
+	s := New(WithEnvPrefix("HELPAPP_"), WithFlagSet(fs))
+	s.SetInt("port", 8080, "Port to listen on")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	fs.PrintDefaults()
+	if !strings.Contains(out.String(), "Port to listen on (env HELPAPP_PORT)") {
+		t.Error("help does not name the env variable:", out.String())
+	}
+}
@@ -0,0 +1,318 @@
+package settingo
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Rule validates the value of a setting once all sources have been parsed.
+//
+// It receives the value with the type the setting was registered with and the
+// source of that value, and returns an error describing why the value is invalid.
+type Rule func(val interface{}, source SourceInfo) error
+
+// AddRules attaches validation rules to a registered setting.
+//
+// Rules are checked at the end of Parse, ParseE and ParseArgs, and every
+// failing rule of every setting is reported together.
+//
+// Example:
+//
+//	s.SetInt("port", 8080, "Port to listen on")
+//	s.AddRules("port", settingo.Min(1), settingo.Max(65535))
+func (s *Settings) AddRules(flagName string, rules ...Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.key(flagName)
+	s.rules[key] = append(s.rules[key], rules...)
+}
+
+// validate checks the rules of every setting and records the failures.
+func (s *Settings) validate() {
+	if s.strictEnv && s.reads(SourceEnv) {
+		s.checkUnknownEnv()
+	}
+	for _, key := range s.names() {
+		val, _ := s.value(key)
+		for _, rule := range s.rules[key] {
+			if err := rule(plainValue(val), s.origins[key]); err != nil {
+				if s.secrets[key] {
+					err = redactError(err, secretItems(plainValue(val))...)
+				}
+				s.addError(key, s.origins[key].Kind, s.format(key, val), err)
+			}
+		}
+	}
+}
+
+// Required fails when the setting was not given by a file, the environment or the command line.
+func Required() Rule {
+	return func(val interface{}, source SourceInfo) error {
+		if source.Kind == SourceDefault || source.Kind == SourceStruct {
+			return errors.New("required setting is not set")
+		}
+		return nil
+	}
+}
+
+// Min fails when a numeric setting is lower than n.
+func Min(n float64) Rule {
+	return func(val interface{}, source SourceInfo) error {
+		num, ok := toFloat(val)
+		if !ok {
+			return fmt.Errorf("min applies to numbers, not %T", val)
+		}
+		if num < n {
+			return fmt.Errorf("must be at least %v", n)
+		}
+		return nil
+	}
+}
+
+// Max fails when a numeric setting is higher than n.
+func Max(n float64) Rule {
+	return func(val interface{}, source SourceInfo) error {
+		num, ok := toFloat(val)
+		if !ok {
+			return fmt.Errorf("max applies to numbers, not %T", val)
+		}
+		if num > n {
+			return fmt.Errorf("must be at most %v", n)
+		}
+		return nil
+	}
+}
+
+// Pattern fails when a string setting, or any item of a slice setting, does not match expr.
+// It panics when expr is not a valid regular expression.
+func Pattern(expr string) Rule {
+	re := regexp.MustCompile(expr)
+	return func(val interface{}, source SourceInfo) error {
+		items, ok := toStrings(val)
+		if !ok {
+			return fmt.Errorf("pattern applies to strings, not %T", val)
+		}
+		for _, item := range items {
+			if !re.MatchString(item) {
+				return fmt.Errorf("%q does not match %s", item, expr)
+			}
+		}
+		return nil
+	}
+}
+
+// OneOf fails when a setting, or any item of a slice setting, is not one of values.
+func OneOf(values ...string) Rule {
+	allowed := make(map[string]bool, len(values))
+	for _, v := range values {
+		allowed[v] = true
+	}
+	return func(val interface{}, source SourceInfo) error {
+		items, ok := toStrings(val)
+		if !ok {
+			return fmt.Errorf("oneof does not apply to %T", val)
+		}
+		for _, item := range items {
+			if !allowed[item] {
+				return fmt.Errorf("%q is not one of %s", item, strings.Join(values, ", "))
+			}
+		}
+		return nil
+	}
+}
+
+// MinLen fails when a string, slice or map setting has fewer than n characters or items.
+func MinLen(n int) Rule {
+	return func(val interface{}, source SourceInfo) error {
+		length, ok := toLen(val)
+		if !ok {
+			return fmt.Errorf("minlen does not apply to %T", val)
+		}
+		if length < n {
+			return fmt.Errorf("length must be at least %d", n)
+		}
+		return nil
+	}
+}
+
+// MaxLen fails when a string, slice or map setting has more than n characters or items.
+func MaxLen(n int) Rule {
+	return func(val interface{}, source SourceInfo) error {
+		length, ok := toLen(val)
+		if !ok {
+			return fmt.Errorf("maxlen does not apply to %T", val)
+		}
+		if length > n {
+			return fmt.Errorf("length must be at most %d", n)
+		}
+		return nil
+	}
+}
+
+// AllowedKeys fails when a map setting contains a key that is not one of keys.
+func AllowedKeys(keys ...string) Rule {
+	allowed := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		allowed[k] = true
+	}
+	return func(val interface{}, source SourceInfo) error {
+		m, ok := val.(map[string][]string)
+		if !ok {
+			return fmt.Errorf("allowed keys apply to maps, not %T", val)
+		}
+		for k := range m {
+			if !allowed[k] {
+				return fmt.Errorf("key %q is not one of %s", k, strings.Join(keys, ", "))
+			}
+		}
+		return nil
+	}
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch typed := val.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
+
+// secretItems returns the parts of a secret value that a rule message could quote:
+// the items of a slice, the keys and items of a map, or the value itself.
+func secretItems(val interface{}) []string {
+	if m, ok := val.(map[string][]string); ok {
+		var items []string
+		for k, v := range m {
+			items = append(items, k)
+			items = append(items, v...)
+		}
+		return items
+	}
+	items, _ := toStrings(val)
+	return items
+}
+
+func toStrings(val interface{}) ([]string, bool) {
+	switch typed := val.(type) {
+	case string:
+		return []string{typed}, true
+	case []string:
+		return typed, true
+	case int:
+		return []string{strconv.Itoa(typed)}, true
+	case int64:
+		return []string{strconv.FormatInt(typed, 10)}, true
+	case uint64:
+		return []string{strconv.FormatUint(typed, 10)}, true
+	case time.Duration:
+		return []string{typed.String()}, true
+	case encoding.TextMarshaler:
+		text, err := typed.MarshalText()
+		return []string{string(text)}, err == nil
+	case fmt.Stringer:
+		return []string{typed.String()}, true
+	}
+	return nil, false
+}
+
+func toLen(val interface{}) (int, bool) {
+	switch typed := val.(type) {
+	case string:
+		return utf8.RuneCountInString(typed), true
+	case []string:
+		return len(typed), true
+	case map[string][]string:
+		return len(typed), true
+	}
+	return 0, false
+}
+
+// parseRules parses the validate struct tag, e.g. `validate:"required,min=1,max=10"`.
+//
+// Rules are separated by commas, the values of oneof and keys by spaces.
+// A pattern takes the rest of the tag, so it must come last when it contains commas.
+func parseRules(tag string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, item := range splitRules(tag) {
+		name, arg := item, ""
+		if eq := strings.Index(item, "="); eq >= 0 {
+			name, arg = item[:eq], item[eq+1:]
+		}
+		switch strings.TrimSpace(name) {
+		case "required":
+			rules = append(rules, Required())
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q", name, item)
+			}
+			if name == "min" {
+				rules = append(rules, Min(n))
+			} else {
+				rules = append(rules, Max(n))
+			}
+		case "minlen", "maxlen":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q", name, item)
+			}
+			if name == "minlen" {
+				rules = append(rules, MinLen(n))
+			} else {
+				rules = append(rules, MaxLen(n))
+			}
+		case "oneof":
+			rules = append(rules, OneOf(strings.Fields(arg)...))
+		case "keys":
+			rules = append(rules, AllowedKeys(strings.Fields(arg)...))
+		case "pattern":
+			if _, err := regexp.Compile(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern rule %q: %w", item, err)
+			}
+			rules = append(rules, Pattern(arg))
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown rule %q", item)
+		}
+	}
+	return rules, nil
+}
+
+// splitRules splits the validate struct tag into its rules.
+func splitRules(tag string) []string {
+	var items []string
+	for tag != "" {
+		var item string
+		if strings.HasPrefix(tag, "pattern=") {
+			item, tag = tag, ""
+		} else if comma := strings.Index(tag, ","); comma >= 0 {
+			item, tag = tag[:comma], tag[comma+1:]
+		} else {
+			item, tag = tag, ""
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ruleChoices returns the values of the oneof rule in the validate struct tag.
+func ruleChoices(tag string) []string {
+	for _, item := range splitRules(tag) {
+		if name, arg, found := strings.Cut(item, "="); found && strings.TrimSpace(name) == "oneof" {
+			return strings.Fields(arg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	testcases := []struct {
+		name  string
+		rule  Rule
+		val   interface{}
+		valid bool
+	}{
+		{"min int", Min(1), 1, true},
+		{"min int too low", Min(1), 0, false},
+		{"max int", Max(10), 10, true},
+		{"max int too high", Max(10), 11, false},
+		{"min on string", Min(1), "a", false},
+		{"pattern", Pattern("^[a-z]+$"), "abc", true},
+		{"pattern mismatch", Pattern("^[a-z]+$"), "ABC", false},
+		{"pattern slice", Pattern("^[a-z]+$"), []string{"a", "B"}, false},
+		{"oneof", OneOf("debug", "info"), "info", true},
+		{"oneof missing", OneOf("debug", "info"), "warn", false},
+		{"oneof int", OneOf("1", "2"), 2, true},
+		{"minlen slice", MinLen(2), []string{"a"}, false},
+		{"maxlen slice", MaxLen(2), []string{"a", "b"}, true},
+		{"maxlen string", MaxLen(2), "äöü", false},
+		{"minlen map", MinLen(1), map[string][]string{}, false},
+		{"allowed keys", AllowedKeys("a"), map[string][]string{"a": nil}, true},
+		{"allowed keys unknown", AllowedKeys("a"), map[string][]string{"b": nil}, false},
+		{"allowed keys on slice", AllowedKeys("a"), []string{"a"}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule(tc.val, SourceInfo{Kind: SourceEnv})
+			if (err == nil) != tc.valid {
+				t.Errorf("rule(%v) = %v, want valid %v", tc.val, err, tc.valid)
+			}
+		})
+	}
+
+	if Required()("", SourceInfo{Kind: SourceDefault}) == nil {
+		t.Error("expected required to fail for a default")
+	}
+	if Required()("", SourceInfo{Kind: SourceFlag}) != nil {
+		t.Error("expected required to pass for a flag")
+	}
+}
+
+func TestRulesReportedTogether(t *testing.T) {
+	os.Setenv("RULEPORT", "0")
+	os.Setenv("RULEHOSTS", "a,b,c")
+	defer os.Unsetenv("RULEPORT")
+	defer os.Unsetenv("RULEHOSTS")
+
+	s := New()
+	s.SetInt("RULEPORT", 8080, "port")
+	s.SetString("RULEKEY", "", "api key")
+	s.SetSlice("RULEHOSTS", []string{}, "hosts", ",")
+	s.AddRules("RULEPORT", Min(1), Max(65535))
+	s.AddRules("RULEKEY", Required())
+	s.AddRules("RULEHOSTS", MaxLen(2))
+
+	err := s.ParseArgs(nil)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 3 {
+		t.Fatal("expected 3 errors, got", err)
+	}
+	if parseErrs[1].Name != "rulekey" || parseErrs[1].Source != SourceDefault {
+		t.Error("unexpected error", parseErrs[1])
+	}
+	if parseErrs[2].Name != "ruleport" || parseErrs[2].Value != "0" || !strings.Contains(parseErrs[2].Error(), "at least 1") {
+		t.Error("unexpected error", parseErrs[2])
+	}
+
+	if err := s.ParseArgs([]string{"-rulekey", "abc", "-ruleport", "80", "-rulehosts", "a"}); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestRulesFromStructTags(t *testing.T) {
+	type config struct {
+		RuleLevel   string   `validate:"required,oneof=debug info"`
+		RuleWorkers int      `validate:"min=1,max=8"`
+		RuleName    string   `validate:"minlen=2,pattern=^[a-z]{2,}$"`
+		RuleTags    []string `validate:"maxlen=1"`
+	}
+	args := os.Args
+	os.Args = []string{"settingo", "-rulelevel", "trace", "-ruleworkers", "9"}
+	defer func() { os.Args = args }()
+
+	err := New().ParseToE(&config{RuleName: "ok", RuleTags: []string{"a"}})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 2 {
+		t.Fatal("expected 2 errors, got", err)
+	}
+	if parseErrs[0].Name != "rulelevel" || parseErrs[1].Name != "ruleworkers" {
+		t.Error("unexpected errors", err)
+	}
+
+	os.Args = []string{"settingo"}
+	type invalid struct {
+		RuleBroken int `validate:"min=low"`
+	}
+	if err := New().ParseToE(&invalid{}); err == nil {
+		t.Error("expected an error for an invalid validate tag")
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := parseRules("required,min=1,max=2,minlen=1,maxlen=2,oneof=a b,keys=x y,pattern=^a,b$")
+	if err != nil || len(rules) != 8 {
+		t.Fatal(len(rules), err)
+	}
+	if rules[7]("a,b", SourceInfo{}) != nil {
+		t.Error("pattern should take the rest of the tag")
+	}
+	for _, tag := range []string{"unknown", "min=", "maxlen=x", "pattern=("} {
+		if _, err := parseRules(tag); err == nil {
+			t.Errorf("expected an error for %q", tag)
+		}
+	}
+}
+
+func TestRulesRedactSecrets(t *testing.T) {
+	s := New()
+	s.Set("apikey", "", "api key")
+	s.SetSlice("tokens", nil, "tokens", ",")
+	s.SetMap("creds", nil, "credentials")
+	s.MarkSecret("apikey")
+	s.MarkSecret("tokens")
+	s.MarkSecret("creds")
+	s.AddRules("apikey", Pattern("^[a-z]+$"))
+	s.AddRules("tokens", OneOf("public"))
+	s.AddRules("creds", AllowedKeys("user"))
+
+	err := s.ParseArgs([]string{"-apikey", "Sk-Live-42", "-tokens", "public,tok-99x", "-creds", "pw:hunter2"})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 3 {
+		t.Fatal("expected 3 errors, got", err)
+	}
+	for _, parseErr := range parseErrs {
+		if parseErr.Value != redacted {
+			t.Error(parseErr.Value, " != ", redacted)
+		}
+	}
+	for _, secret := range []string{"Sk-Live-42", "tok-99x", "hunter2", "pw"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Error(err, " contains ", secret)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package settingo
 
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+)
+
 // SETTINGS is the global instance of the Settings struct for the settingo package.
 //
 // It provides a package-level access point to manage application settings.
@@ -10,6 +17,9 @@ package settingo
 //
 //	SETTINGS is initialized with empty maps for all setting types when the package is loaded.
 //	Settings are registered and accessed through the package-level functions.
+//	Use New for a registry that is independent of SETTINGS.
+//	Unlike other Settings, SETTINGS registers its flags on flag.CommandLine, so they
+//	coexist with flags the program defines through the flag package.
 //
 // Example:
 //
@@ -23,17 +33,26 @@ package settingo
 //		outputDir := settingo.Get("outputDir")
 //		println("Output directory:", outputDir)
 //	}
-var SETTINGS = Settings{
-	msg:              make(map[string]string),
-	VarString:        make(map[string]string),
-	VarInt:           make(map[string]int),
-	VarMap:           make(map[string]map[string][]string),
-	VarSlice:         make(map[string][]string),
-	VarSliceSep:      make(map[string]string),
-	Parsers:          make(map[string]func(string) string),
-	ParsersInt:       make(map[string]func(int) int),
-	VarBool:          make(map[string]bool),
-	ContextualCasing: true,
+var SETTINGS Settings
+
+func init() {
+	SETTINGS.ContextualCasing = true
+	SETTINGS.initialize()
+	SETTINGS.flagSet = flag.CommandLine
+
+	// flag.CommandLine calls flag.Usage, so a usage function the program
+	// sets on either replaces this one.
+	flagUsage := flag.Usage
+	flag.Usage = func() {
+		SETTINGS.mu.RLock()
+		registered := len(SETTINGS.names()) > 0
+		SETTINGS.mu.RUnlock()
+		if !registered {
+			flagUsage()
+			return
+		}
+		SETTINGS.printUsage(flag.CommandLine)
+	}
 }
 
 // Get retrieves the current string value of a registered string setting from the global SETTINGS instance.
@@ -115,13 +134,132 @@ func SetInt(flagName string, defaultVar int, message string) {
 	SETTINGS.SetInt(flagName, defaultVar, message)
 }
 
+// SetFloat is a package-level function to register a floating point setting within the global SETTINGS instance.
+//
+// It delegates to the SetFloat method of the global SETTINGS variable.
+// Input that is not a valid number is reported by ParseE as a parse error.
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "ratio").
+//	            Used for environment variable lookup and command-line flag parsing.
+//	defaultVar: The default floating point value.
+//	message:    The help message.
+//
+// Example:
+//
+//		settingo.SetFloat("ratio", 0.75, "Fraction of requests to sample")
+//
+//	 // Can be set via:
+//	 // - Environment variable: RATIO=0.5
+//	 // - Command-line flag: --ratio=0.5
+func SetFloat(flagName string, defaultVar float64, message string) {
+	SETTINGS.SetFloat(flagName, defaultVar, message)
+}
+
+// SetInt64 is a package-level function to register a 64-bit integer setting within the global SETTINGS instance.
+//
+// It delegates to the SetInt64 method of the global SETTINGS variable.
+// Values that do not fit in 64 bits are reported by ParseE as a parse error.
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "max_bytes").
+//	            Used for environment variable lookup and command-line flag parsing.
+//	defaultVar: The default integer value.
+//	message:    The help message.
+//
+// Example:
+//
+//		settingo.SetInt64("max_bytes", 1<<40, "Maximum upload size in bytes")
+//
+//	 // Can be set via:
+//	 // - Environment variable: MAX_BYTES=2199023255552
+//	 // - Command-line flag: --max_bytes=2199023255552
+func SetInt64(flagName string, defaultVar int64, message string) {
+	SETTINGS.SetInt64(flagName, defaultVar, message)
+}
+
+// SetUint is a package-level function to register an unsigned integer setting within the global SETTINGS instance.
+//
+// It delegates to the SetUint method of the global SETTINGS variable.
+// Negative values and values that do not fit in 64 bits are reported by ParseE as a parse error.
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "workers").
+//	            Used for environment variable lookup and command-line flag parsing.
+//	defaultVar: The default unsigned integer value.
+//	message:    The help message.
+//
+// Example:
+//
+//		settingo.SetUint("workers", 4, "Number of worker goroutines")
+//
+//	 // Can be set via:
+//	 // - Environment variable: WORKERS=8
+//	 // - Command-line flag: --workers=8
+func SetUint(flagName string, defaultVar uint64, message string) {
+	SETTINGS.SetUint(flagName, defaultVar, message)
+}
+
+// SetDuration is a package-level function to register a duration setting within the global SETTINGS instance.
+//
+// It delegates to the SetDuration method of the global SETTINGS variable.
+// Input is parsed with time.ParseDuration, e.g. "30s" or "1h15m".
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "timeout").
+//	            Used for environment variable lookup and command-line flag parsing.
+//	defaultVar: The default duration.
+//	message:    The help message.
+//
+// Example:
+//
+//		settingo.SetDuration("timeout", 30*time.Second, "Request timeout")
+//
+//	 // Can be set via:
+//	 // - Environment variable: TIMEOUT=1m
+//	 // - Command-line flag: --timeout=1m
+func SetDuration(flagName string, defaultVar time.Duration, message string) {
+	SETTINGS.SetDuration(flagName, defaultVar, message)
+}
+
+// SetTime is a package-level function to register a time setting within the global SETTINGS instance.
+//
+// It delegates to the SetTime method of the global SETTINGS variable.
+// Input is parsed with each layout in turn until one matches, the first layout
+// is also used when the value is shown in help or Dump output.
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "since").
+//	            Used for environment variable lookup and command-line flag parsing.
+//	defaultVar: The default time, the zero time for no default.
+//	message:    The help message.
+//	layouts:    Optional time layouts, time.RFC3339 when none are given.
+//
+// Example:
+//
+//		settingo.SetTime("since", time.Time{}, "Only process events after this date", "2006-01-02", time.RFC3339)
+//
+//	 // Can be set via:
+//	 // - Environment variable: SINCE=2024-01-31
+//	 // - Command-line flag: --since=2024-01-31T12:00:00Z
+func SetTime(flagName string, defaultVar time.Time, message string, layouts ...string) {
+	SETTINGS.SetTime(flagName, defaultVar, message, layouts...)
+}
+
 // SetBool is a package-level function to register a boolean setting within the global SETTINGS instance.
 //
 // It delegates to the SetBool method of the global SETTINGS variable.
 // Registers a boolean setting that can be configured via environment variables or command-line flags.
 //
-// When set via environment variables or command-line flags, the string values
-// are interpreted as boolean using the truthiness function (see truthiness()).
+// On the command line the flag can be given without a value, -verbose, and
+// is turned off with -verbose=false or the generated -no-verbose flag.
+// Values are interpreted using the truthiness function (see truthiness()),
+// words it does not know are reported by ParseE as a parse error.
 //
 // Args:
 //
@@ -135,8 +273,8 @@ func SetInt(flagName string, defaultVar int, message string) {
 //		settingo.SetBool("verbose", false, "Enable verbose output")
 //
 //	 // Can be set via:
-//	 // - Environment variable: VERBOSE=true or VERBOSE=y or VERBOSE=yes
-//	 // - Command-line flag: --verbose=true or --verbose=y or --verbose=yes
+//	 // - Environment variable: VERBOSE=true, VERBOSE=yes, VERBOSE=on or VERBOSE=1
+//	 // - Command-line flag: --verbose, --verbose=false or --no-verbose
 func SetBool(flagName string, defaultVar bool, message string) {
 	SETTINGS.SetBool(flagName, defaultVar, message)
 }
@@ -323,6 +461,278 @@ func GetSlice(flagName string) []string {
 	return SETTINGS.GetSlice(flagName)
 }
 
+// GetFloat retrieves the current value of a registered floating point setting from the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the GetFloat method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current floating point value of the setting from the global SETTINGS instance.
+func GetFloat(flagName string) float64 {
+	return SETTINGS.GetFloat(flagName)
+}
+
+// GetInt64 retrieves the current value of a registered 64-bit integer setting from the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the GetInt64 method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current 64-bit integer value of the setting from the global SETTINGS instance.
+func GetInt64(flagName string) int64 {
+	return SETTINGS.GetInt64(flagName)
+}
+
+// GetUint retrieves the current value of a registered unsigned integer setting from the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the GetUint method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current unsigned integer value of the setting from the global SETTINGS instance.
+func GetUint(flagName string) uint64 {
+	return SETTINGS.GetUint(flagName)
+}
+
+// GetDuration retrieves the current value of a registered duration setting from the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the GetDuration method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current duration of the setting from the global SETTINGS instance.
+func GetDuration(flagName string) time.Duration {
+	return SETTINGS.GetDuration(flagName)
+}
+
+// GetTime retrieves the current value of a registered time setting from the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the GetTime method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current time of the setting from the global SETTINGS instance.
+func GetTime(flagName string) time.Time {
+	return SETTINGS.GetTime(flagName)
+}
+
+// IsRegistered reports whether a setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the IsRegistered method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag.
+//
+// Returns:
+//
+//	True when a setting of any type is registered under the name.
+func IsRegistered(flagName string) bool {
+	return SETTINGS.IsRegistered(flagName)
+}
+
+// Type returns the Go type of a setting registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the Type method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag.
+//
+// Returns:
+//
+//	The type, e.g. "int", "[]string" or "time.Duration", or an empty string for unknown names.
+func Type(flagName string) string {
+	return SETTINGS.Type(flagName)
+}
+
+// SetPanicOnUnknown makes the Get functions of the global SETTINGS instance panic on mistakes.
+//
+// It delegates to the SetPanicOnUnknown method of the global SETTINGS variable.
+// With it enabled GetInt("prot") or GetInt on a string setting panic instead of
+// returning a zero value, which is meant for development builds and tests.
+//
+// Args:
+//
+//	enabled: Whether Get functions panic on unknown names and type mismatches.
+//
+// Example:
+//
+//	settingo.SetPanicOnUnknown(os.Getenv("APP_ENV") == "development")
+func SetPanicOnUnknown(enabled bool) {
+	SETTINGS.SetPanicOnUnknown(enabled)
+}
+
+// Lookup is like Get, but also reports whether a string setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the Lookup method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no string setting has that name.
+func Lookup(flagName string) (string, bool) {
+	return SETTINGS.Lookup(flagName)
+}
+
+// LookupInt is like GetInt, but also reports whether a int setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupInt method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no int setting has that name.
+func LookupInt(flagName string) (int, bool) {
+	return SETTINGS.LookupInt(flagName)
+}
+
+// LookupBool is like GetBool, but also reports whether a bool setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupBool method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no bool setting has that name.
+func LookupBool(flagName string) (bool, bool) {
+	return SETTINGS.LookupBool(flagName)
+}
+
+// LookupMap is like GetMap, but also reports whether a map[string][]string setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupMap method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no map[string][]string setting has that name.
+func LookupMap(flagName string) (map[string][]string, bool) {
+	return SETTINGS.LookupMap(flagName)
+}
+
+// LookupSlice is like GetSlice, but also reports whether a []string setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupSlice method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no []string setting has that name.
+func LookupSlice(flagName string) ([]string, bool) {
+	return SETTINGS.LookupSlice(flagName)
+}
+
+// LookupFloat is like GetFloat, but also reports whether a float64 setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupFloat method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no float64 setting has that name.
+func LookupFloat(flagName string) (float64, bool) {
+	return SETTINGS.LookupFloat(flagName)
+}
+
+// LookupInt64 is like GetInt64, but also reports whether a int64 setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupInt64 method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no int64 setting has that name.
+func LookupInt64(flagName string) (int64, bool) {
+	return SETTINGS.LookupInt64(flagName)
+}
+
+// LookupUint is like GetUint, but also reports whether a uint64 setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupUint method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no uint64 setting has that name.
+func LookupUint(flagName string) (uint64, bool) {
+	return SETTINGS.LookupUint(flagName)
+}
+
+// LookupDuration is like GetDuration, but also reports whether a time.Duration setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupDuration method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no time.Duration setting has that name.
+func LookupDuration(flagName string) (time.Duration, bool) {
+	return SETTINGS.LookupDuration(flagName)
+}
+
+// LookupTime is like GetTime, but also reports whether a time.Time setting of that name is registered in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LookupTime method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to retrieve.
+//
+// Returns:
+//
+//	The current value and true, or the zero value and false when no time.Time setting has that name.
+func LookupTime(flagName string) (time.Time, bool) {
+	return SETTINGS.LookupTime(flagName)
+}
+
 // Parse parses settings from both OS environment variables and command-line flags using the global SETTINGS instance.
 //
 // It's a package-level function that delegates to the Parse method of the global SETTINGS variable.
@@ -364,3 +774,515 @@ func Parse() {
 func ParseTo(to interface{}) {
 	SETTINGS.ParseTo(to)
 }
+
+// ParseE parses settings from environment variables and command-line flags into the
+// global SETTINGS instance and reports every value that could not be converted.
+//
+// It's a package-level function that delegates to the ParseE method of the global SETTINGS variable.
+//
+// Unlike Parse, which keeps the default of a setting whose input is invalid,
+// ParseE returns a ParseErrors naming each failing setting, the source it was
+// read from and the raw value, so that a program can refuse to start.
+//
+// Returns:
+//
+//	nil when every setting was parsed, otherwise a ParseErrors.
+//
+// Example:
+//
+//	settingo.SetInt("port", 8080, "Port to listen on")
+//	if err := settingo.ParseE(); err != nil {
+//		log.Fatal(err)
+//	}
+func ParseE() error {
+	return SETTINGS.ParseE()
+}
+
+// ParseToE is like ParseTo, but returns the errors collected while loading the
+// struct and parsing the environment and command line.
+//
+// It's a package-level function that delegates to the ParseToE method of the global SETTINGS variable.
+//
+// Fields with a type settingo cannot handle are reported with the source "struct".
+//
+// Args:
+//
+//	to: A pointer to a struct whose fields will be updated with parsed settings.
+//
+// Returns:
+//
+//	nil when every setting was parsed, otherwise a ParseErrors.
+func ParseToE(to interface{}) error {
+	return SETTINGS.ParseToE(to)
+}
+
+// ParseArgs parses environment variables and the given command-line arguments into the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the ParseArgs method of the global SETTINGS variable.
+//
+// The global SETTINGS instance registers its flags on flag.CommandLine, which exits the
+// program on invalid input. Use a Settings instance of your own to parse arguments
+// repeatedly and to receive flag errors as a returned error.
+//
+// Args:
+//
+//	args: The command-line arguments to parse, without the program name.
+//
+// Returns:
+//
+//	nil when every setting was parsed, otherwise the flag error or a ParseErrors.
+func ParseArgs(args []string) error {
+	return SETTINGS.ParseArgs(args)
+}
+
+// Args returns the non-flag command-line arguments left over by the last parse of the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the Args method of the global SETTINGS variable.
+func Args() []string {
+	return SETTINGS.Args()
+}
+
+// SetEnvPrefix is a package-level function to namespace the environment variables of the global SETTINGS instance.
+//
+// It delegates to the SetEnvPrefix method of the global SETTINGS variable.
+// Call it before Parse, the prefix applies to every registered setting.
+//
+// Args:
+//
+//	prefix:   Prepended to the environment variable of every setting (e.g., "MYAPP_").
+//	fallback: Also read the variable without the prefix when the prefixed one is not set.
+//
+// Example:
+//
+//		settingo.SetInt("port", 8080, "Port to listen on")
+//		settingo.SetEnvPrefix("MYAPP_", true)
+//		settingo.Parse()
+//
+//	 // Read from MYAPP_PORT, or from PORT when MYAPP_PORT is not set.
+func SetEnvPrefix(prefix string, fallback bool) {
+	SETTINGS.SetEnvPrefix(prefix, fallback)
+}
+
+// SetNameMapper is a package-level function to choose how the global SETTINGS instance names flags and environment variables.
+//
+// It delegates to the SetNameMapper method of the global SETTINGS variable.
+// Call it before registering settings, as the flag name is the key a setting is stored under.
+//
+// Args:
+//
+//	mapper: The NameMapper to use, e.g. ConventionalNames.
+//
+// Example:
+//
+//		settingo.SetNameMapper(settingo.ConventionalNames)
+//		settingo.SetInt("maxConnections", 100, "Maximum number of connections")
+//
+//	 // Can be set via:
+//	 // - Environment variable: MAX_CONNECTIONS=200
+//	 // - Command-line flag: --max-connections=200
+func SetNameMapper(mapper NameMapper) {
+	SETTINGS.SetNameMapper(mapper)
+}
+
+// SetAlias is a package-level function to register extra flag names for a setting of the global SETTINGS instance.
+//
+// It delegates to the SetAlias method of the global SETTINGS variable.
+// Single letter aliases work like the shorthands of Unix tools: boolean ones
+// can be grouped, -vq, and a value can be attached, -p8080.
+//
+// Args:
+//
+//	flagName: The name of the registered setting (e.g., "port").
+//	aliases:  The additional flag names (e.g., "p", "listen-port").
+//
+// Example:
+//
+//		settingo.SetInt("port", 8080, "Port to listen on")
+//		settingo.SetAlias("port", "p")
+//
+//	 // Can be set via:
+//	 // - Command-line flag: --port=8081, --port 8081, -p 8081 or -p8081
+func SetAlias(flagName string, aliases ...string) {
+	SETTINGS.SetAlias(flagName, aliases...)
+}
+
+// AddCommand is a package-level function to add a subcommand to the global SETTINGS instance.
+//
+// It delegates to the AddCommand method of the global SETTINGS variable.
+// The command gets its own Settings when it has none, register its settings there.
+//
+// Args:
+//
+//	cmd: The command, with its Name, Help and the Run function it dispatches to.
+//
+// Returns:
+//
+//	cmd, so its settings can be registered right away.
+//
+// Example:
+//
+//		serve := settingo.AddCommand(&settingo.Command{
+//			Name: "serve",
+//			Help: "Start the server",
+//			Run:  func(args []string) error { return serve(ctx) },
+//		})
+//		serve.Settings.SetInt("port", 8080, "Port to listen on")
+//
+//	 // Run as: ./tool serve --port=8081
+func AddCommand(cmd *Command) *Command {
+	return SETTINGS.AddCommand(cmd)
+}
+
+// MarkPersistent is a package-level function to share settings of the global SETTINGS instance with all subcommands.
+//
+// It delegates to the MarkPersistent method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagNames: The names of registered settings.
+//
+// Example:
+//
+//		settingo.SetBool("verbose", false, "Verbose output")
+//		settingo.MarkPersistent("verbose")
+//
+//	 // Both work: ./tool -verbose serve and ./tool serve -verbose
+func MarkPersistent(flagNames ...string) {
+	SETTINGS.MarkPersistent(flagNames...)
+}
+
+// Execute is a package-level function to parse the command line of the global SETTINGS instance and run a subcommand.
+//
+// It delegates to the Execute method of the global SETTINGS variable.
+//
+// Args:
+//
+//	args: The command line arguments, usually os.Args[1:].
+//
+// Returns:
+//
+//	The parse errors of the settings, an error for a missing or unknown
+//	command, or the error returned by the Run function of the command.
+//
+// Example:
+//
+//	if err := settingo.Execute(os.Args[1:]); err != nil {
+//		log.Fatal(err)
+//	}
+func Execute(args []string) error {
+	return SETTINGS.Execute(args)
+}
+
+// SetStrictEnv is a package-level function to reject unknown environment variables in the global SETTINGS instance.
+//
+// It delegates to the SetStrictEnv method of the global SETTINGS variable.
+// Every variable starting with the env prefix must belong to a setting; without
+// a prefix nothing is checked. Parse exits and ParseE returns an error naming
+// the closest settings.
+//
+// Args:
+//
+//	strict: Whether to check the environment.
+//
+// Example:
+//
+//	settingo.SetEnvPrefix("MYAPP_", false)
+//	settingo.SetStrictEnv(true)
+//	settingo.SetInt("max_connections", 100, "Maximum number of connections")
+//	settingo.Parse()
+//
+//	// MYAPP_MAX_CONECTIONS=200 fails with:
+//	// env MYAPP_MAX_CONECTIONS: invalid value "200": unknown environment variable, did you mean MYAPP_MAX_CONNECTIONS?
+func SetStrictEnv(strict bool) {
+	SETTINGS.SetStrictEnv(strict)
+}
+
+// SetConfigFile is a package-level function to register the setting that holds the configuration file path
+// within the global SETTINGS instance.
+//
+// It delegates to the SetConfigFile method of the global SETTINGS variable.
+// The path itself can be given as a default, an environment variable or a command-line flag.
+// The file is loaded during Parse, before environment variables and command-line flags are applied.
+//
+// Args:
+//
+//	flagName:    The name of the setting flag (e.g., "config").
+//	defaultPath: The default path; a missing file at this path is ignored.
+//	message:     The help message.
+//
+// Example:
+//
+//	settingo.SetConfigFile("config", "/etc/myapp/config.yaml", "Path to the configuration file")
+//	settingo.Parse()
+//
+//	// Can be set via:
+//	// - Environment variable: CONFIG=./dev.toml
+//	// - Command-line flag: --config=./dev.toml
+func SetConfigFile(flagName, defaultPath, message string) {
+	SETTINGS.SetConfigFile(flagName, defaultPath, message)
+}
+
+// LoadFile reads a JSON, YAML or TOML configuration file into the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LoadFile method of the global SETTINGS variable.
+//
+// Call it before Parse to provide a base configuration that environment variables
+// and command-line flags can override.
+//
+// Args:
+//
+//	path: The path of the file; the format is chosen by its extension (.json, .yaml, .yml or .toml).
+//
+// Returns:
+//
+//	An error when the file cannot be read or decoded, or a ParseErrors for values that could not be converted.
+func LoadFile(path string) error {
+	return SETTINGS.LoadFile(path)
+}
+
+// LoadDotEnv reads variables from dotenv files for the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the LoadDotEnv method of the global SETTINGS variable.
+//
+// The variables are used as if they were environment variables when Parse runs, without
+// modifying the process environment. Variables set in the process environment take precedence.
+//
+// Args:
+//
+//	paths: The dotenv files to read, in order; later files override earlier ones.
+//	       Defaults to ".env" when no paths are given.
+//
+// Returns:
+//
+//	An error when a file cannot be read or contains a malformed line.
+//
+// Example:
+//
+//	if err := settingo.LoadDotEnv(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+//		log.Fatal(err)
+//	}
+//	settingo.Parse()
+func LoadDotEnv(paths ...string) error {
+	return SETTINGS.LoadDotEnv(paths...)
+}
+
+// Source returns where the current value of a setting in the global SETTINGS instance came from.
+//
+// It's a package-level function that delegates to the Source method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName: The name of the setting flag to inspect.
+//
+// Returns:
+//
+//	A SourceInfo with the kind of source (default, struct, file, env or flag) and,
+//	depending on the kind, the environment variable or flag name and the file and line.
+//
+// Example:
+//
+//	settingo.Parse()
+//	log.Println("port set by", settingo.Source("port"))
+//	// port set by env PORT
+func Source(flagName string) SourceInfo {
+	return SETTINGS.Source(flagName)
+}
+
+// SetSecret is a package-level function to register a secret string setting within the global SETTINGS instance.
+//
+// It delegates to the SetSecret method of the global SETTINGS variable.
+// A secret setting behaves like a string setting, but its value and default are redacted by Dump.
+//
+// Args:
+//
+//	flagName:   The name of the setting flag (e.g., "db-password").
+//	defaultVar: The default string value.
+//	message:    The help message.
+//
+// Example:
+//
+//	settingo.SetSecret("apikey", "", "API key for authentication")
+func SetSecret(flagName, defaultVar, message string) {
+	SETTINGS.SetSecret(flagName, defaultVar, message)
+}
+
+// Dump writes the effective configuration of the global SETTINGS instance to w.
+//
+// It's a package-level function that delegates to the Dump method of the global SETTINGS variable.
+//
+// Every registered setting is written with its type, effective value, default,
+// source and help message. Settings registered with SetSecret, or loaded from a
+// struct field tagged `secret:"true"`, are redacted.
+//
+// Args:
+//
+//	w:      The writer to write to, e.g. os.Stderr or a log writer.
+//	format: FormatText, FormatJSON or FormatYAML.
+//
+// Example:
+//
+//	settingo.Parse()
+//	settingo.Dump(os.Stderr, settingo.FormatText)
+func Dump(w io.Writer, format Format) error {
+	return SETTINGS.Dump(w, format)
+}
+
+// AddRules is a package-level function to attach validation rules to a setting of the global SETTINGS instance.
+//
+// It delegates to the AddRules method of the global SETTINGS variable.
+// The rules are checked after all sources have been parsed. Parse prints every
+// violation and exits, ParseE and ParseArgs return them as a ParseErrors.
+//
+// Struct fields loaded with ParseTo can declare the same rules in a validate tag:
+//
+//	Port  int    `settingo:"Port to listen on" validate:"min=1,max=65535"`
+//	Level string `settingo:"Log level" validate:"required,oneof=debug info warn"`
+//
+// Args:
+//
+//	flagName: The name of a registered setting.
+//	rules:    Rules such as Required(), Min(1), Max(10), Pattern("^[a-z]+$"),
+//	          OneOf("a", "b"), MinLen(1), MaxLen(3) or AllowedKeys("a", "b").
+//
+// Example:
+//
+//	settingo.SetString("level", "info", "Log level")
+//	settingo.AddRules("level", settingo.OneOf("debug", "info", "warn"))
+func AddRules(flagName string, rules ...Rule) {
+	SETTINGS.AddRules(flagName, rules...)
+}
+
+// OnChange registers a callback for changes of a setting in the global SETTINGS instance.
+//
+// It's a package-level function that delegates to the OnChange method of the global SETTINGS variable.
+//
+// The callback is called by Reload, and therefore by Watch, with the old and the
+// new value of the setting whenever a reload changed it.
+//
+// Args:
+//
+//	flagName: The name of a registered setting.
+//	fn:       The callback, receiving values of the type the setting was registered with.
+//
+// Example:
+//
+//	settingo.OnChange("loglevel", func(old, new interface{}) {
+//		logger.SetLevel(new.(string))
+//	})
+func OnChange(flagName string, fn func(old, new interface{})) {
+	SETTINGS.OnChange(flagName, fn)
+}
+
+// Reload parses all sources of the global SETTINGS instance again and swaps in the new values.
+//
+// It's a package-level function that delegates to the Reload method of the global SETTINGS variable.
+//
+// Returns:
+//
+//	nil when the new values were applied, otherwise the errors that kept the current values in place.
+func Reload() error {
+	return SETTINGS.Reload()
+}
+
+// Watch reloads the global SETTINGS instance on SIGHUP and on modification of its configuration files.
+//
+// It's a package-level function that delegates to the Watch method of the global SETTINGS variable.
+//
+// Watch blocks until ctx is done, so it is usually started in its own goroutine after Parse.
+//
+// Example:
+//
+//	settingo.Parse()
+//	go settingo.Watch(ctx)
+func Watch(ctx context.Context) error {
+	return SETTINGS.Watch(ctx)
+}
+
+// SetGroup puts settings of the global SETTINGS instance under a heading in the help output.
+//
+// It's a package-level function that delegates to the SetGroup method of the global SETTINGS variable.
+//
+// Args:
+//
+//	group:     The heading, e.g. "Database".
+//	flagNames: The names of registered settings.
+//
+// Example:
+//
+//	settingo.SetGroup("Database", "DB_HOST", "DB_PORT")
+func SetGroup(group string, flagNames ...string) {
+	SETTINGS.SetGroup(group, flagNames...)
+}
+
+// SetUsageTemplate replaces the help output of the global SETTINGS instance with a text/template.
+//
+// It's a package-level function that delegates to the SetUsageTemplate method of the global SETTINGS variable.
+//
+// The template is executed with a UsageData.
+//
+// Returns:
+//
+//	An error when the template could not be parsed.
+func SetUsageTemplate(text string) error {
+	return SETTINGS.SetUsageTemplate(text)
+}
+
+// PrintUsage writes the help output of the global SETTINGS instance to w.
+//
+// It's a package-level function that delegates to the PrintUsage method of the global SETTINGS variable.
+func PrintUsage(w io.Writer) error {
+	return SETTINGS.PrintUsage(w)
+}
+
+// SetChoices restricts a setting of the global SETTINGS instance to values and completes them in the shell.
+//
+// It's a package-level function that delegates to the SetChoices method of the global SETTINGS variable.
+//
+// Example:
+//
+//	settingo.Set("LOGLEVEL", "info", "Log level")
+//	settingo.SetChoices("LOGLEVEL", "debug", "info", "warn", "error")
+func SetChoices(flagName string, values ...string) {
+	SETTINGS.SetChoices(flagName, values...)
+}
+
+// SetFileCompletion completes a setting of the global SETTINGS instance with file paths in the shell.
+//
+// It's a package-level function that delegates to the SetFileCompletion method of the global SETTINGS variable.
+//
+// Args:
+//
+//	flagName:   The name of a registered setting.
+//	extensions: The extensions completed files are limited to, e.g. "yaml", none for any file.
+func SetFileCompletion(flagName string, extensions ...string) {
+	SETTINGS.SetFileCompletion(flagName, extensions...)
+}
+
+// SetDirCompletion completes a setting of the global SETTINGS instance with directory paths in the shell.
+//
+// It's a package-level function that delegates to the SetDirCompletion method of the global SETTINGS variable.
+func SetDirCompletion(flagName string) {
+	SETTINGS.SetDirCompletion(flagName)
+}
+
+// GenerateCompletion writes a completion script for the global SETTINGS instance to w.
+//
+// It's a package-level function that delegates to the GenerateCompletion method of the global SETTINGS variable.
+//
+// Args:
+//
+//	w:     Where the script is written, usually os.Stdout.
+//	shell: "bash", "zsh" or "fish".
+//
+// Example:
+//
+//	settingo.AddCommand(&settingo.Command{
+//		Name: "completion",
+//		Help: "Print the completion script for bash, zsh or fish",
+//		Run: func(args []string) error {
+//			return settingo.GenerateCompletion(os.Stdout, args[0])
+//		},
+//	})
+func GenerateCompletion(w io.Writer, shell string) error {
+	return SETTINGS.GenerateCompletion(w, shell)
+}
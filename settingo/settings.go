@@ -1,22 +1,38 @@
 package settingo
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 )
 
-func truthiness(s string) bool {
-	thruthy := map[string]bool{
-		"y":    true,
-		"true": true,
-		"yes":  true,
+// truthiness converts the input of a boolean setting. It accepts true/false,
+// yes/no, on/off and 1/0 in any case, and their first letters. An empty
+// value is false, so VERBOSE= turns a setting off.
+func truthiness(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "", "0", "f", "false", "n", "no", "off":
+		return false, nil
 	}
-	return thruthy[s]
+	return false, errors.New("expected true/false, yes/no, on/off or 1/0")
 }
 
+// Settings is a registry of settings read from defaults, configuration files,
+// environment variables and command line flags. Use New to create one, the zero value is not ready for use.
+//
+// The methods of Settings are safe for concurrent use, so settings can be read
+// while others are registered or a reload is in progress. The exported Var maps
+// are not guarded, use the Get methods instead of reading them concurrently.
 type Settings struct {
 	msg              map[string]string
 	VarString        map[string]string
@@ -25,17 +41,102 @@ type Settings struct {
 	VarMap           map[string]map[string][]string
 	VarSlice         map[string][]string
 	VarSliceSep      map[string]string
+	VarFloat         map[string]float64
+	VarInt64         map[string]int64
+	VarUint          map[string]uint64
+	VarDuration      map[string]time.Duration
+	VarTime          map[string]time.Time
+	VarTimeLayouts   map[string][]string
+	VarValue         map[string]flag.Value
 	Parsers          map[string]func(string) string
 	ParsersInt       map[string]func(int) int
 	ContextualCasing bool
+	errs             ParseErrors
+	flagSet          *flag.FlagSet
+	args             []string
+	envPrefix        string
+	envFallback      bool
+	sources          map[SourceKind]bool
+	configKey        string
+	dotenv           map[string]dotenvValue
+	origins          map[string]SourceInfo
+	defaults         map[string]interface{}
+	secrets          map[string]bool
+	rules            map[string][]Rule
+	fromStruct       map[string]bool
+	argv             []string
+	cmdline          map[string]flagInput
+	files            []string
+	configFile       string
+	dotenvPaths      []string
+	mu               *sync.RWMutex
+	reloadMu         *sync.Mutex
+	callbacks        map[string][]func(old, new interface{})
+	watchInterval    time.Duration
+	reloadErrors     func(error)
+	fieldTypes       map[string]reflect.Type
+	envNames         map[string]string
+	nameMapper       NameMapper
+	registered       map[string]string
+	aliases          map[string]string
+	name             string
+	parent           *Settings
+	commands         []*Command
+	persistent       map[string]bool
+	strictEnv        bool
+	panicOnUnknown   bool
+	groups           map[string]string
+	completions      map[string]completion
+	usageTemplate    *template.Template
+	stagedFrom       *Settings
 }
 
-func (s *Settings) Set(flagName, defaultVar, message string) {
+// key returns the name a setting is stored under.
+func (s *Settings) key(flagName string) string {
+	if s.nameMapper != nil {
+		flagName = s.nameMapper.FlagName(flagName)
+	}
 	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
+		return strings.ToLower(flagName)
+	}
+	return flagName
+}
+
+// owner returns the Settings the flags of s belong to: the one s was staged from,
+// or s itself.
+func (s *Settings) owner() *Settings {
+	if s.stagedFrom != nil {
+		return s.stagedFrom
 	}
-	s.msg[flagName] = message
-	s.VarString[flagName] = defaultVar
+	return s
+}
+
+// register records the help message of a setting that has just been stored
+// under key and keeps a copy of its value as the default.
+func (s *Settings) register(key, message string) {
+	s.msg[key] = message
+	s.origins[key] = SourceInfo{Kind: SourceDefault}
+	delete(s.fieldTypes, key)
+	delete(s.envNames, key)
+	if val, found := s.value(key); found {
+		s.defaults[key] = copyValue(val)
+	}
+}
+
+// set stores the value of a setting and registers it, returning its key.
+// The caller holds the lock.
+func (s *Settings) set(flagName string, val interface{}, message string) string {
+	key := s.key(flagName)
+	s.setValue(key, val)
+	s.register(key, message)
+	s.registered[key] = flagName
+	return key
+}
+
+func (s *Settings) Set(flagName, defaultVar, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
 func (s *Settings) SetString(flagName, defaultVar, message string) {
@@ -43,206 +144,804 @@ func (s *Settings) SetString(flagName, defaultVar, message string) {
 }
 
 func (s *Settings) SetInt(flagName string, defaultVar int, message string) {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarInt[flagName] = defaultVar
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
 func (s *Settings) SetBool(flagName string, defaultVar bool, message string) {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarBool[flagName] = defaultVar
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
 func (s *Settings) SetMap(flagName string, defaultVar map[string][]string, message string) {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarMap[flagName] = defaultVar
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
 func (s *Settings) SetSlice(flagName string, defaultVar []string, message string, sep string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setSlice(flagName, defaultVar, message, sep)
+}
+
+func (s *Settings) setSlice(flagName string, defaultVar []string, message string, sep string) {
 	if sep == "" {
 		sep = ","
 	}
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarSlice[flagName] = defaultVar
-	s.VarSliceSep[flagName] = sep
+	s.VarSliceSep[s.key(flagName)] = sep
+	s.set(flagName, defaultVar, message)
 }
 
 func (s *Settings) SetParsed(flagName, defaultVar, message string, parserFunc func(string) string) {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarString[flagName] = defaultVar
-	s.Parsers[flagName] = parserFunc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.set(flagName, defaultVar, message)
+	s.Parsers[key] = parserFunc
 }
 
 func (s *Settings) SetParsedInt(flagName, defaultVar, message string, parserFunc func(int) int) {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	s.msg[flagName] = message
-	s.VarString[flagName] = defaultVar
-	s.ParsersInt[flagName] = parserFunc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.set(flagName, defaultVar, message)
+	s.ParsersInt[key] = parserFunc
 }
 
-func (s Settings) Get(flagName string) string {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	return s.VarString[flagName]
+func (s *Settings) SetFloat(flagName string, defaultVar float64, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
-func (s Settings) GetInt(flagName string) int {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
-	}
-	return s.VarInt[flagName]
+func (s *Settings) SetInt64(flagName string, defaultVar int64, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
 }
 
-func (s Settings) GetBool(flagName string) bool {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
+func (s *Settings) SetUint(flagName string, defaultVar uint64, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
+}
+
+func (s *Settings) SetDuration(flagName string, defaultVar time.Duration, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(flagName, defaultVar, message)
+}
+
+// SetTime registers a time setting. Input is parsed with the given layouts in
+// order, the first one is also used to format the value; without layouts
+// time.RFC3339 is used.
+func (s *Settings) SetTime(flagName string, defaultVar time.Time, message string, layouts ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setTime(flagName, defaultVar, message, layouts)
+}
+
+func (s *Settings) setTime(flagName string, defaultVar time.Time, message string, layouts []string) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
 	}
-	return s.VarBool[flagName]
+	s.VarTimeLayouts[s.key(flagName)] = layouts
+	s.set(flagName, defaultVar, message)
 }
 
-func (s Settings) GetMap(flagName string) map[string][]string {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
+func (s *Settings) Get(flagName string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarString, flagName, "Get")
+	return val
+}
+
+func (s *Settings) GetInt(flagName string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarInt, flagName, "GetInt")
+	return val
+}
+
+func (s *Settings) GetBool(flagName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarBool, flagName, "GetBool")
+	return val
+}
+
+func (s *Settings) GetMap(flagName string) map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarMap, flagName, "GetMap")
+	return val
+}
+
+func (s *Settings) GetSlice(flagName string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarSlice, flagName, "GetSlice")
+	return val
+}
+
+func (s *Settings) GetFloat(flagName string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarFloat, flagName, "GetFloat")
+	return val
+}
+
+func (s *Settings) GetInt64(flagName string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarInt64, flagName, "GetInt64")
+	return val
+}
+
+func (s *Settings) GetUint(flagName string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarUint, flagName, "GetUint")
+	return val
+}
+
+func (s *Settings) GetDuration(flagName string) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarDuration, flagName, "GetDuration")
+	return val
+}
+
+func (s *Settings) GetTime(flagName string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, _ := lookup(s, s.VarTime, flagName, "GetTime")
+	return val
+}
+
+// nameInUse reports whether key is a setting or an alias of one.
+func (s *Settings) nameInUse(key string) bool {
+	if _, found := s.msg[key]; found {
+		return true
 	}
-	return s.VarMap[flagName]
+	_, found := s.aliases[key]
+	return found
 }
 
-func (s Settings) GetSlice(flagName string) []string {
-	if s.ContextualCasing {
-		flagName = strings.ToLower(flagName)
+// names returns every registered setting name in sorted order.
+func (s *Settings) names() []string {
+	seen := make(map[string]bool)
+	for key := range s.VarString {
+		seen[key] = true
+	}
+	for key := range s.VarInt {
+		seen[key] = true
+	}
+	for key := range s.VarBool {
+		seen[key] = true
+	}
+	for key := range s.VarMap {
+		seen[key] = true
+	}
+	for key := range s.VarSlice {
+		seen[key] = true
+	}
+	for key := range s.VarFloat {
+		seen[key] = true
+	}
+	for key := range s.VarInt64 {
+		seen[key] = true
+	}
+	for key := range s.VarUint {
+		seen[key] = true
 	}
-	return s.VarSlice[flagName]
+	for key := range s.VarDuration {
+		seen[key] = true
+	}
+	for key := range s.VarTime {
+		seen[key] = true
+	}
+	for key := range s.VarValue {
+		seen[key] = true
+	}
+	names := make([]string, 0, len(seen))
+	for key := range seen {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
 }
 
-func (s *Settings) HandleCMDLineInput() {
-	parsedString := make(map[string]*string)
-	for key, val := range s.VarString {
-		var newV = flag.String(key, val, s.msg[key])
-		parsedString[key] = newV
-	}
-	parsedInt := make(map[string]*int)
-	for key, val := range s.VarInt {
-		var newV = flag.Int(key, val, s.msg[key])
-		parsedInt[key] = newV
-	}
-	parsedBool := make(map[string]*string)
-	for key, val := range s.VarBool {
-		var newV = flag.String(key, strconv.FormatBool(val), s.msg[key])
-		parsedBool[key] = newV
-	}
-	parsedMap := make(map[string]*string)
-	for key, val := range s.VarMap {
-		var newV = flag.String(key, ParseMapToLine(val), s.msg[key])
-		parsedBool[key] = newV
-	}
-	parsedSlice := make(map[string]*string)
-	for key, val := range s.VarSlice {
-		var newV = flag.String(key, strings.Join(val, s.VarSliceSep[key]), s.msg[key])
-		parsedSlice[key] = newV
-	}
-	flag.Parse()
-
-	for key, val := range parsedString {
+// setFromString converts raw to the type the setting was registered with and stores it.
+// The stored value is left untouched when raw cannot be converted, except for
+// maps: their valid items are stored and the discarded ones reported, see applied.
+func (s *Settings) setFromString(key, raw string) error {
+	if _, found := s.VarString[key]; found {
 		if parseFunc, found := s.Parsers[key]; found {
-			s.VarString[key] = parseFunc(*val)
+			s.VarString[key] = parseFunc(raw)
 		} else {
-			s.VarString[key] = *val
+			s.VarString[key] = raw
 		}
 	}
-	for key, val := range parsedInt {
+	if _, found := s.VarInt[key]; found {
+		num, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
 		if parseFunc, found := s.ParsersInt[key]; found {
-			s.VarInt[key] = parseFunc(*val)
-		} else {
-			s.VarInt[key] = *val
+			num = parseFunc(num)
+		}
+		s.VarInt[key] = num
+	}
+	if _, found := s.VarBool[key]; found {
+		truth, err := truthiness(raw)
+		if err != nil {
+			return err
+		}
+		s.VarBool[key] = truth
+	}
+	if _, found := s.VarMap[key]; found {
+		parsed, discarded := parseLine(raw)
+		s.VarMap[key] = parsed
+		if len(discarded) > 0 {
+			return discardedItems(discarded)
+		}
+	}
+	if _, found := s.VarSlice[key]; found {
+		s.VarSlice[key] = strings.Split(raw, s.VarSliceSep[key])
+	}
+	if _, found := s.VarFloat[key]; found {
+		num, err := strconv.ParseFloat(raw, s.bitSize(key))
+		if err != nil {
+			return err
+		}
+		s.VarFloat[key] = num
+	}
+	if _, found := s.VarInt64[key]; found {
+		num, err := strconv.ParseInt(raw, 10, s.bitSize(key))
+		if err != nil {
+			return err
+		}
+		s.VarInt64[key] = num
+	}
+	if _, found := s.VarUint[key]; found {
+		num, err := strconv.ParseUint(raw, 10, s.bitSize(key))
+		if err != nil {
+			return err
 		}
+		s.VarUint[key] = num
 	}
-	for key, val := range parsedBool {
-		s.VarBool[key] = truthiness(*val)
+	if _, found := s.VarDuration[key]; found {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.VarDuration[key] = duration
 	}
-	for key, val := range parsedMap {
-		s.VarMap[key] = ParseLineToMap(*val)
+	if _, found := s.VarTime[key]; found {
+		parsed, err := parseTime(raw, s.VarTimeLayouts[key])
+		if err != nil {
+			return err
+		}
+		s.VarTime[key] = parsed
 	}
+	if val, found := s.VarValue[key]; found {
+		if err := val.Set(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	for key, val := range parsedSlice {
-		s.VarSlice[key] = strings.Split(*val, s.VarSliceSep[key])
+// parseTime parses raw with the first layout that accepts it.
+// When none does the error of the first layout is returned.
+func parseTime(raw string, layouts []string) (time.Time, error) {
+	var first error
+	for _, layout := range layouts {
+		parsed, err := time.Parse(layout, raw)
+		if err == nil {
+			return parsed, nil
+		}
+		if first == nil {
+			first = err
+		}
 	}
+	return time.Time{}, first
 }
 
-func (s *Settings) HandleOSInput() {
-	for key := range s.VarString {
-		lookupKey := key
-		if s.ContextualCasing {
-			lookupKey = strings.ToUpper(key)
+// bitSize returns the size in bits a numeric setting must fit in.
+// Settings loaded from struct fields such as int16 or float32 are limited to
+// the size of the field, all others to 64 bits.
+func (s *Settings) bitSize(key string) int {
+	if typ, found := s.fieldTypes[key]; found {
+		return typ.Bits()
+	}
+	return 64
+}
+
+// value returns the current value of a setting with the type it was registered with.
+func (s *Settings) value(key string) (interface{}, bool) {
+	if val, found := s.VarString[key]; found {
+		return val, true
+	}
+	if val, found := s.VarInt[key]; found {
+		return val, true
+	}
+	if val, found := s.VarBool[key]; found {
+		return val, true
+	}
+	if val, found := s.VarMap[key]; found {
+		return val, true
+	}
+	if val, found := s.VarSlice[key]; found {
+		return val, true
+	}
+	if val, found := s.VarFloat[key]; found {
+		return val, true
+	}
+	if val, found := s.VarInt64[key]; found {
+		return val, true
+	}
+	if val, found := s.VarUint[key]; found {
+		return val, true
+	}
+	if val, found := s.VarDuration[key]; found {
+		return val, true
+	}
+	if val, found := s.VarTime[key]; found {
+		return val, true
+	}
+	if val, found := s.VarValue[key]; found {
+		return val, true
+	}
+	return nil, false
+}
+
+// typeName returns the Go type of a setting, e.g. "int" or "[]string".
+// Settings loaded from struct fields report the type the field is declared with, e.g. "int16".
+func (s *Settings) typeName(key string) string {
+	val, found := s.value(key)
+	if !found {
+		return ""
+	}
+	if typ, found := s.fieldTypes[key]; found {
+		return typ.String()
+	}
+	if fieldValue, ok := val.(*fieldValue); ok {
+		return fieldValue.val.Type().String()
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+// formatValue returns the current value of a setting in its command line notation.
+func (s *Settings) formatValue(key string) string {
+	val, _ := s.value(key)
+	return s.format(key, val)
+}
+
+// format returns val, a value of the setting key, in its command line notation.
+func (s *Settings) format(key string, val interface{}) string {
+	switch typed := val.(type) {
+	case string:
+		return typed
+	case int:
+		return strconv.Itoa(typed)
+	case bool:
+		return strconv.FormatBool(typed)
+	case map[string][]string:
+		return ParseMapToLine(typed)
+	case []string:
+		return strings.Join(typed, s.VarSliceSep[key])
+	case float64:
+		return strconv.FormatFloat(typed, 'g', -1, s.bitSize(key))
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case uint64:
+		return strconv.FormatUint(typed, 10)
+	case time.Duration:
+		return typed.String()
+	case time.Time:
+		if typed.IsZero() {
+			return ""
+		}
+		return typed.Format(s.VarTimeLayouts[key][0])
+	case flag.Value:
+		return typed.String()
+	}
+	return ""
+}
+
+// copyValue returns a copy of a setting value that shares no memory with val.
+// A nil slice or map stays nil, so that the copy compares equal to val.
+func copyValue(val interface{}) interface{} {
+	switch typed := val.(type) {
+	case []string:
+		if typed == nil {
+			return typed
 		}
-		varEnv, found := os.LookupEnv(lookupKey)
-		if found {
-			s.VarString[key] = varEnv
+		return append([]string{}, typed...)
+	case map[string][]string:
+		if typed == nil {
+			return typed
 		}
+		copied := make(map[string][]string, len(typed))
+		for k, v := range typed {
+			copied[k] = append([]string(nil), v...)
+		}
+		return copied
+	case *fieldValue:
+		return typed.clone()
 	}
-	for key := range s.VarInt {
-		lookupKey := key
-		if s.ContextualCasing {
-			lookupKey = strings.ToUpper(key)
-		}
-		varEnv, found := os.LookupEnv(lookupKey)
-		if found {
-			if num, err := strconv.Atoi(varEnv); err == nil {
-				s.VarInt[key] = num
+	return val
+}
+
+// flagValue holds the raw command line input of a setting until it is converted.
+type flagValue struct {
+	value  string
+	isBool bool
+	owner  *Settings
+}
+
+func (f *flagValue) String() string {
+	return f.value
+}
+
+func (f *flagValue) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given without a value, -verbose.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// flagInput is a flag given on the command line, kept so that Reload can apply it again.
+type flagInput struct {
+	name  string
+	value string
+}
+
+// negatedValue is the --no-name flag of the boolean setting key,
+// it stores the negation of its input in the flagValue of the setting.
+type negatedValue struct {
+	key    string
+	target *flagValue
+}
+
+func (n *negatedValue) String() string {
+	return ""
+}
+
+func (n *negatedValue) Set(value string) error {
+	truth, err := truthiness(value)
+	if err != nil {
+		return err
+	}
+	n.target.value = strconv.FormatBool(!truth)
+	return nil
+}
+
+func (n *negatedValue) IsBoolFlag() bool {
+	return true
+}
+
+// bareBoolValue rejects `-verbose false`, or any other word truthiness accepts
+// such as no, 0 or off. Boolean flags do not take the next argument as their
+// value, so the word would become a positional argument while the flag turns
+// the setting on. Before boolean flags this form was required, so it is
+// reported like other invalid flags rather than accepted silently.
+func bareBoolValue(fs *flag.FlagSet, args []string) error {
+	rest := fs.Args()
+	i := len(args) - len(rest) - 1
+	if len(rest) == 0 || i < 0 || !strings.HasPrefix(args[i], "-") || strings.Contains(args[i], "=") {
+		return nil
+	}
+	if _, err := truthiness(rest[0]); err != nil || strings.TrimSpace(rest[0]) == "" {
+		return nil
+	}
+	f := fs.Lookup(strings.TrimLeft(args[i], "-"))
+	if f == nil || takesValue(f) {
+		return nil
+	}
+	err := fmt.Errorf("boolean flag %s does not take a separate value, use %s=%s", args[i], args[i], rest[0])
+	return failParse(fs, err, true)
+}
+
+// failParse reports err like fs reports an invalid flag: err is printed, followed
+// by the usage when showUsage is set, and fs exits, panics or returns err as its
+// error handling says.
+func failParse(fs *flag.FlagSet, err error, showUsage bool) error {
+	fmt.Fprintln(fs.Output(), err)
+	if showUsage {
+		fs.Usage()
+	}
+	switch fs.ErrorHandling() {
+	case flag.ExitOnError:
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// newFlagSet returns the flag set the command line is parsed with.
+// Settings without an explicit flag set get a fresh one on every call,
+// so that parsing can be repeated without redefining flags.
+func (s *Settings) newFlagSet(errorHandling flag.ErrorHandling) *flag.FlagSet {
+	if s.flagSet != nil {
+		return s.flagSet
+	}
+	fs := flag.NewFlagSet(s.programName(), errorHandling)
+	fs.Usage = s.usageFunc(fs)
+	return fs
+}
+
+// handleArgs registers every setting on fs, parses args and stores the flags that were given.
+// Persistent settings of parent commands are registered as well and stored in their own Settings.
+func (s *Settings) handleArgs(fs *flag.FlagSet, args []string) error {
+	taken := s.defineFlags(fs, nil)
+	s.registerAliases(fs)
+	ancestors := s.ancestors()
+	for _, parent := range ancestors {
+		parent.mu.Lock()
+		defer parent.mu.Unlock()
+		taken = append(taken, parent.defineFlags(fs, parent.persistent)...)
+		parent.registerAliases(fs)
+	}
+	switch len(taken) {
+	case 0:
+	case 1:
+		return failParse(fs, fmt.Errorf("flag -%s is defined by both the program and settingo", taken[0]), false)
+	default:
+		return failParse(fs, fmt.Errorf("flags -%s are defined by both the program and settingo", strings.Join(taken, ", -")), false)
+	}
+	expanded := s.expandShortFlags(fs, args)
+	err := fs.Parse(expanded)
+	if err == nil {
+		err = bareBoolValue(fs, expanded)
+	}
+	s.args = fs.Args()
+	s.cmdline = make(map[string]flagInput)
+	s.applyFlags(fs)
+	for _, parent := range ancestors {
+		parent.applyFlags(fs)
+	}
+	return flagSuggestion(fs, err)
+}
+
+// defineFlags registers the settings on fs, or only those in subset when it is not nil.
+// It returns the names of settings whose flag the program already defined on fs
+// itself, e.g. with flag.Int on flag.CommandLine, those are left alone.
+func (s *Settings) defineFlags(fs *flag.FlagSet, subset map[string]bool) []string {
+	var taken []string
+	for _, key := range s.names() {
+		if subset != nil && !subset[key] {
+			continue
+		}
+		if f := fs.Lookup(key); f != nil {
+			switch f.Value.(type) {
+			case *flagValue, *negatedValue:
+			default:
+				taken = append(taken, key)
 			}
+			continue
+		}
+		_, isBool := s.VarBool[key]
+		value := &flagValue{value: s.formatValue(key), isBool: isBool, owner: s.owner()}
+		fs.Var(value, key, s.usage(key))
+		if negated := "no-" + key; isBool && fs.Lookup(negated) == nil && !s.nameInUse(negated) {
+			fs.Var(&negatedValue{key: key, target: value}, negated, "set -"+key+" to false")
 		}
 	}
-	for key := range s.VarBool {
-		lookupKey := key
-		if s.ContextualCasing {
-			lookupKey = strings.ToUpper(key)
+	return taken
+}
+
+// applyFlags stores the flags given on fs that belong to s.
+func (s *Settings) applyFlags(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		key := s.canonical(f.Name)
+		val, ok := f.Value.(*flagValue)
+		if negated, isNegated := f.Value.(*negatedValue); isNegated {
+			key, val, ok = negated.key, negated.target, true
 		}
-		varEnv, found := os.LookupEnv(lookupKey)
-		if found {
-			s.VarBool[key] = truthiness(varEnv)
+		if !ok || val.owner != s.owner() {
+			return
 		}
-	}
-	for key := range s.VarMap {
-		lookupKey := key
-		if s.ContextualCasing {
-			lookupKey = strings.ToUpper(key)
+		s.cmdline[key] = flagInput{name: f.Name, value: val.value}
+		if err := s.setFromString(key, val.value); err != nil {
+			s.addError(key, SourceFlag, val.value, err)
+			if !applied(err) {
+				return
+			}
 		}
-		varEnv, found := os.LookupEnv(lookupKey)
-		if found {
-			s.VarMap[key] = ParseLineToMap(varEnv)
+		s.origins[key] = SourceInfo{Kind: SourceFlag, Name: f.Name}
+	})
+}
+
+func (s *Settings) HandleCMDLineInput() {
+	s.parseStaged(func(staged *Settings) {
+		staged.handleArgs(staged.newFlagSet(flag.ExitOnError), os.Args[1:])
+	})
+}
+
+// envName returns the environment variable a setting is read from.
+// Dots separating nested struct fields become underscores, db.host is read from DB_HOST.
+func (s *Settings) envName(key string) string {
+	if name, found := s.envNames[key]; found {
+		return name
+	}
+	if s.nameMapper != nil {
+		name, found := s.registered[key]
+		if !found {
+			name = key
 		}
+		return s.envPrefix + s.nameMapper.EnvName(name)
 	}
-	for key := range s.VarSlice {
-		lookupKey := key
-		if s.ContextualCasing {
-			lookupKey = strings.ToUpper(key)
+	if s.ContextualCasing {
+		key = strings.ToUpper(key)
+	}
+	return s.envPrefix + strings.ReplaceAll(key, ".", "_")
+}
+
+// lookupSettingEnv returns the environment variable that sets key and its value.
+// With fallback enabled the name without the env prefix is tried when the
+// prefixed one is not set.
+func (s *Settings) lookupSettingEnv(key string) (string, string, bool) {
+	name := s.envName(key)
+	if val, found := s.lookupEnv(name); found {
+		return name, val, true
+	}
+	if _, override := s.envNames[key]; override || !s.envFallback || s.envPrefix == "" {
+		return name, "", false
+	}
+	name = strings.TrimPrefix(name, s.envPrefix)
+	val, found := s.lookupEnv(name)
+	return name, val, found
+}
+
+// usage returns the help message of a setting for the command line help,
+// naming its environment variable when that is not simply the upper-cased flag.
+func (s *Settings) usage(key string) string {
+	name := s.envName(key)
+	if name == strings.ToUpper(key) {
+		return s.msg[key]
+	}
+	if s.msg[key] == "" {
+		return "env " + name
+	}
+	return s.msg[key] + " (env " + name + ")"
+}
+
+// SetEnvPrefix prepends prefix to the environment variable of every setting.
+// With fallback the variable without the prefix is read when the prefixed one is not set.
+func (s *Settings) SetEnvPrefix(prefix string, fallback bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.envPrefix = prefix
+	s.envFallback = fallback
+}
+
+func (s *Settings) HandleOSInput() {
+	s.parseStaged(func(staged *Settings) {
+		staged.handleOSInput()
+	})
+}
+
+func (s *Settings) handleOSInput() {
+	for _, key := range s.names() {
+		lookupKey, varEnv, found := s.lookupSettingEnv(key)
+		if !found {
+			continue
 		}
-		varEnv, found := os.LookupEnv(lookupKey)
-		if found {
-			s.VarSlice[key] = strings.Split(varEnv, s.VarSliceSep[key])
+		if err := s.setFromString(key, varEnv); err != nil {
+			s.addError(key, SourceEnv, varEnv, err)
+			if !applied(err) {
+				continue
+			}
 		}
+		origin := SourceInfo{Kind: SourceEnv, Name: lookupKey}
+		if _, inProcess := os.LookupEnv(lookupKey); !inProcess {
+			origin.File, origin.Line = s.dotenv[lookupKey].path, s.dotenv[lookupKey].line
+		}
+		s.origins[key] = origin
 	}
 }
 
+// Parse reads environment variables and command line flags into the settings.
+// Values that cannot be converted are ignored, use ParseE to have them reported.
+// The valid items of a map value are applied, the discarded ones are reported by ParseE.
+//
+// Settings that break their validation rules are printed and the program
+// exits with status 2, like the flag package does for invalid flags.
 func (s *Settings) Parse() {
-	s.HandleOSInput()
-	s.HandleCMDLineInput()
+	var err error
+	s.parseStaged(func(staged *Settings) {
+		staged.parse(os.Args[1:], flag.ExitOnError)
+		staged.errs = nil
+		staged.validate()
+		err = staged.takeErrors()
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// parse reads every enabled source in order of increasing precedence:
+// configuration file, environment and command line. The caller holds the lock,
+// or s is a staged copy, see parseStaged.
+func (s *Settings) parse(args []string, errorHandling flag.ErrorHandling) error {
+	s.argv = args
+	if s.reads(SourceFile) {
+		s.handleFileInput(args)
+	}
+	if s.reads(SourceEnv) {
+		s.handleOSInput()
+	}
+	if !s.reads(SourceFlag) {
+		s.args = args
+		return nil
+	}
+	return s.handleArgs(s.newFlagSet(errorHandling), args)
+}
+
+// ParseE is like Parse, but returns a ParseErrors listing every setting whose
+// input could not be converted or that breaks its validation rules,
+// including failures recorded by LoadStruct.
+func (s *Settings) ParseE() error {
+	return s.ParseArgs(os.Args[1:])
+}
+
+// ParseArgs parses the configuration file, environment variables and the given command line arguments.
+//
+// Flags are parsed with the flag set of the Settings, or with a new flag set on
+// every call, so args can be parsed repeatedly, e.g. from tests.
+// A malformed command line, an unknown flag or -help is returned as the error of
+// the flag package, otherwise the result is the same as for ParseE.
+func (s *Settings) ParseArgs(args []string) error {
+	var err error
+	s.parseStaged(func(staged *Settings) {
+		if err = staged.parse(args, flag.ContinueOnError); err != nil {
+			staged.errs = nil
+			return
+		}
+		staged.validate()
+		err = staged.takeErrors()
+	})
+	return err
+}
+
+// parseStaged runs parse on copies of s and its ancestors and stores the
+// values it parsed. Only the read locks are held while parse runs, so the
+// usage functions, parsers and rules it calls may read settings, as with Reload.
+func (s *Settings) parseStaged(parse func(staged *Settings)) {
+	chain := append([]*Settings{s}, s.ancestors()...)
+	copies := make([]*Settings, len(chain))
+	parsedFrom := make([]map[string]interface{}, len(chain))
+	func() {
+		for i, live := range chain {
+			live.mu.RLock()
+			defer live.mu.RUnlock()
+			copies[i] = live.snapshot()
+			parsedFrom[i] = live.values()
+			if i > 0 {
+				copies[i-1].parent = copies[i]
+			}
+		}
+		parse(copies[0])
+	}()
+	for i, live := range chain {
+		live.commit(copies[i], parsedFrom[i])
+	}
+}
+
+// Args returns the non-flag arguments left over by the last parse.
+func (s *Settings) Args() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.args
 }
 
 func (s *Settings) ParseTo(to interface{}) {
@@ -251,43 +950,222 @@ func (s *Settings) ParseTo(to interface{}) {
 	s.UpdateStruct(to)
 }
 
-// LoadStruct registers a struct's fields with SETTINGS
-func (s *Settings) LoadStruct(cfg interface{}) {
+// ParseToE is like ParseTo, but returns the errors collected by ParseE.
+// The struct is updated with every value that could be converted.
+func (s *Settings) ParseToE(to interface{}) error {
+	s.LoadStruct(to)
+	err := s.ParseE()
+	s.UpdateStruct(to)
+	return err
+}
+
+// structValue returns the struct cfg points to, or records an error when cfg is not a struct.
+func (s *Settings) structValue(cfg interface{}) (reflect.Value, bool) {
 	val := reflect.ValueOf(cfg)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
+	if val.Kind() != reflect.Struct {
+		s.addError(fmt.Sprintf("%T", cfg), SourceStruct, "", errors.New("expected a struct or a pointer to a struct"))
+		return val, false
+	}
+	return val, true
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// LoadStruct registers a struct's fields with SETTINGS.
+//
+// Nested structs and pointers to structs are walked recursively, their fields
+// are named after the path to them: Config.DB.Host becomes the setting DB.HOST,
+// read from the flag -db.host and the environment variable DB_HOST.
+// The fields of embedded structs are registered as if they were declared in
+// the embedding struct.
+//
+// Fields are configured with struct tags:
+//
+//	settingo:"help"  the help message, or "-" to skip the field
+//	flag:"port"      the setting and flag name instead of the field name
+//	env:"APP_PORT"   the environment variable, used as is without the env prefix
+//	default:"8080"   the default when the field holds its zero value
+//	sep:"|"          the separator of a slice field
+//	secret:"true"    redact the value in Dump
+//	required:"true"  fail validation when no input sets the field
+//	validate:"..."   validation rules, see parseRules
+//	group:"Database" the heading in the help output
+//	complete:"file"  complete file paths in the shell, see parseCompletion
+func (s *Settings) LoadStruct(cfg interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	val, ok := s.structValue(cfg)
+	if !ok {
+		return
+	}
+	s.loadStruct(val, "")
+}
+
+func (s *Settings) loadStruct(val reflect.Value, prefix string) {
 	typ := val.Type()
 
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 		value := val.Field(i)
-		name := strings.ToUpper(field.Name)
+		if field.Tag.Get("settingo") == "-" {
+			continue
+		}
+		if field.Anonymous && !isValueField(field.Type) && field.Type != timeType {
+			if embedded, ok := structElem(value); ok {
+				s.loadStruct(embedded, prefix)
+				continue
+			}
+		}
+		name, ok := s.fieldName(field, prefix)
+		if !ok {
+			continue
+		}
 		help := field.Tag.Get("settingo")
 
+		if isValueField(field.Type) {
+			s.set(name, newFieldValue(value), help)
+			s.registerField(name, field, value)
+			continue
+		}
+
 		switch value.Kind() {
 		case reflect.String:
-			s.SetString(name, value.String(), help)
+			s.set(name, value.String(), help)
 		case reflect.Int:
-			s.SetInt(name, int(value.Int()), help)
+			s.set(name, int(value.Int()), help)
 		case reflect.Bool:
-			s.SetBool(name, value.Bool(), help)
+			s.set(name, value.Bool(), help)
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.Type == durationType {
+				s.set(name, time.Duration(value.Int()), help)
+			} else {
+				s.set(name, value.Int(), help)
+				s.fieldTypes[s.key(name)] = field.Type
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			s.set(name, value.Uint(), help)
+			s.fieldTypes[s.key(name)] = field.Type
+		case reflect.Float32, reflect.Float64:
+			s.set(name, value.Float(), help)
+			s.fieldTypes[s.key(name)] = field.Type
 		case reflect.Slice:
 			if value.Type().Elem().Kind() == reflect.String {
 				slice := make([]string, value.Len())
 				for i := 0; i < value.Len(); i++ {
 					slice[i] = value.Index(i).String()
 				}
-				s.SetSlice(name, slice, help, s.VarSliceSep[strings.ToLower(name)])
+				sep := field.Tag.Get("sep")
+				if sep == "" {
+					sep = s.VarSliceSep[s.key(name)]
+				}
+				s.setSlice(name, slice, help, sep)
+			} else {
+				s.addError(name, SourceStruct, "", fmt.Errorf("unsupported field type %s", field.Type))
+				continue
 			}
 		case reflect.Map:
 			if value.Type().Key().Kind() == reflect.String &&
 				value.Type().Elem().Kind() == reflect.Slice &&
 				value.Type().Elem().Elem().Kind() == reflect.String {
 				m := value.Interface().(map[string][]string)
-				s.SetMap(name, m, help)
+				s.set(name, m, help)
+			} else {
+				s.addError(name, SourceStruct, "", fmt.Errorf("unsupported field type %s", field.Type))
+				continue
+			}
+		case reflect.Struct:
+			if field.Type == timeType {
+				var layouts []string
+				if layout := field.Tag.Get("layout"); layout != "" {
+					layouts = []string{layout}
+				}
+				s.setTime(name, value.Interface().(time.Time), help, layouts)
+			} else {
+				s.loadStruct(value, name+".")
+				continue
+			}
+		case reflect.Ptr:
+			if nested, ok := structElem(value); ok {
+				s.loadStruct(nested, name+".")
+			} else {
+				s.addError(name, SourceStruct, "", fmt.Errorf("unsupported field type %s", field.Type))
 			}
+			continue
+		default:
+			s.addError(name, SourceStruct, "", fmt.Errorf("unsupported field type %s", field.Type))
+			continue
 		}
+		s.registerField(name, field, value)
+	}
+}
+
+// fieldName returns the setting name of a struct field below prefix, and false
+// for unexported fields. A flag tag replaces the field name, not the prefix, so
+// DB.Host and Cache.Host tagged flag:"host" are db.host and cache.host.
+// A NameMapper gets the field name as it was written.
+func (s *Settings) fieldName(field reflect.StructField, prefix string) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	if name := field.Tag.Get("flag"); name != "" {
+		return prefix + name, true
+	}
+	if s.nameMapper != nil {
+		return prefix + field.Name, true
+	}
+	return prefix + strings.ToUpper(field.Name), true
+}
+
+// registerField records that the setting name was loaded from field, which
+// holds value, and applies the field's tags.
+func (s *Settings) registerField(name string, field reflect.StructField, value reflect.Value) {
+	key := s.key(name)
+	s.origins[key] = SourceInfo{Kind: SourceStruct}
+	s.fromStruct[key] = true
+	if env := field.Tag.Get("env"); env != "" {
+		s.envNames[key] = env
+	}
+	if group := field.Tag.Get("group"); group != "" {
+		s.groups[key] = group
+	}
+	if secret, _ := strconv.ParseBool(field.Tag.Get("secret")); secret {
+		s.secrets[key] = true
+	}
+	if def := field.Tag.Get("default"); def != "" && value.IsZero() {
+		err := s.setFromString(key, def)
+		if err != nil {
+			s.addError(name, SourceStruct, def, err)
+		}
+		if val, found := s.value(key); found && (err == nil || applied(err)) {
+			s.defaults[key] = copyValue(val)
+		}
+	}
+	if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+		s.rules[key] = append(s.rules[key], Required())
+	}
+	if complete := field.Tag.Get("complete"); complete != "" {
+		comp, err := parseCompletion(complete)
+		if err != nil {
+			s.addError(name, SourceStruct, complete, err)
+		}
+		s.completions[key] = comp
+	}
+	rules, err := parseRules(field.Tag.Get("validate"))
+	if err != nil {
+		s.addError(name, SourceStruct, field.Tag.Get("validate"), err)
+		return
+	}
+	s.rules[key] = append(s.rules[key], rules...)
+	if choices := ruleChoices(field.Tag.Get("validate")); len(choices) > 0 {
+		comp := s.completions[key]
+		comp.values = choices
+		s.completions[key] = comp
 	}
 }
 
@@ -297,23 +1175,61 @@ func (s *Settings) UpdateStruct(cfg interface{}) {
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
+	if val.Kind() != reflect.Struct || !val.CanSet() {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.updateStruct(val, "")
+}
+
+func (s *Settings) updateStruct(val reflect.Value, prefix string) {
 	typ := val.Type()
 
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 		value := val.Field(i)
-		name := strings.ToUpper(field.Name)
+		if field.Tag.Get("settingo") == "-" {
+			continue
+		}
+		if field.Anonymous && !isValueField(field.Type) && field.Type != timeType {
+			if embedded, ok := settableStructElem(value); ok {
+				s.updateStruct(embedded, prefix)
+				continue
+			}
+		}
+		name, ok := s.fieldName(field, prefix)
+		if !ok {
+			continue
+		}
+		key := s.key(name)
+
+		if fieldValue, found := s.VarValue[key].(*fieldValue); found && isValueField(field.Type) {
+			value.Set(fieldValue.clone().val)
+			continue
+		}
 
 		switch value.Kind() {
 		case reflect.String:
-			value.SetString(s.Get(name))
+			value.SetString(s.VarString[key])
 		case reflect.Int:
-			value.SetInt(int64(s.GetInt(name)))
+			value.SetInt(int64(s.VarInt[key]))
 		case reflect.Bool:
-			value.SetBool(s.GetBool(name))
+			value.SetBool(s.VarBool[key])
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.Type == durationType {
+				value.SetInt(int64(s.VarDuration[key]))
+			} else {
+				value.SetInt(s.VarInt64[key])
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			value.SetUint(s.VarUint[key])
+		case reflect.Float32, reflect.Float64:
+			value.SetFloat(s.VarFloat[key])
 		case reflect.Slice:
 			if value.Type().Elem().Kind() == reflect.String {
-				slice := s.GetSlice(name)
+				slice := s.VarSlice[key]
 				newSlice := reflect.MakeSlice(value.Type(), len(slice), len(slice))
 				for i, s := range slice {
 					newSlice.Index(i).SetString(s)
@@ -324,7 +1240,7 @@ func (s *Settings) UpdateStruct(cfg interface{}) {
 			if value.Type().Key().Kind() == reflect.String &&
 				value.Type().Elem().Kind() == reflect.Slice &&
 				value.Type().Elem().Elem().Kind() == reflect.String {
-				m := s.GetMap(name)
+				m := s.VarMap[key]
 				newMap := reflect.MakeMap(value.Type())
 				for k, v := range m {
 					sliceValue := reflect.MakeSlice(value.Type().Elem(), len(v), len(v))
@@ -335,6 +1251,43 @@ func (s *Settings) UpdateStruct(cfg interface{}) {
 				}
 				value.Set(newMap)
 			}
+		case reflect.Struct:
+			if field.Type == timeType {
+				value.Set(reflect.ValueOf(s.VarTime[key]))
+			} else {
+				s.updateStruct(value, name+".")
+			}
+		case reflect.Ptr:
+			if nested, ok := settableStructElem(value); ok {
+				s.updateStruct(nested, name+".")
+			}
+		}
+	}
+}
+
+// structElem returns the struct val holds or points to. For a nil pointer the
+// zero value of the struct is returned, so its defaults can still be registered.
+func structElem(val reflect.Value) (reflect.Value, bool) {
+	if val.Kind() == reflect.Ptr {
+		if val.Type().Elem().Kind() != reflect.Struct {
+			return val, false
+		}
+		if val.IsNil() {
+			return reflect.New(val.Type().Elem()).Elem(), true
+		}
+		val = val.Elem()
+	}
+	return val, val.Kind() == reflect.Struct
+}
+
+// settableStructElem is like structElem, but allocates nil pointers so the
+// struct can be filled in.
+func settableStructElem(val reflect.Value) (reflect.Value, bool) {
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		if val.Type().Elem().Kind() != reflect.Struct || !val.CanSet() {
+			return val, false
 		}
+		val.Set(reflect.New(val.Type().Elem()))
 	}
+	return structElem(val)
 }
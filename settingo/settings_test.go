@@ -1,10 +1,15 @@
 package settingo
 
 import (
+	"errors"
+	"flag"
+	"io"
 	"net/url"
 	"os"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type TestConfig struct {
@@ -94,3 +99,115 @@ func Test_struct_types_default(t *testing.T) {
 		t.Error(config.FooParse, " != ", expectedFooParse)
 	}
 }
+
+func TestParseArgsRepeatedly(t *testing.T) {
+	s := New()
+	s.SetInt("ARGSPORT", 8080, "port")
+	s.SetMap("ARGSHEADERS", map[string][]string{}, "headers")
+	s.SetSlice("ARGSHOSTS", []string{"localhost"}, "hosts", ",")
+
+	os.Setenv("ARGSPORT", "9090")
+	defer os.Unsetenv("ARGSPORT")
+
+	for i := 0; i < 2; i++ {
+		err := s.ParseArgs([]string{"-argsheaders", "accept:json", "-argshosts=a,b", "rest"})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+	if s.GetInt("ARGSPORT") != 9090 {
+		t.Error(s.GetInt("ARGSPORT"), " != ", 9090)
+	}
+	if !reflect.DeepEqual(s.GetMap("ARGSHEADERS"), map[string][]string{"accept": {"json"}}) {
+		t.Error(s.GetMap("ARGSHEADERS"))
+	}
+	if !reflect.DeepEqual(s.GetSlice("ARGSHOSTS"), []string{"a", "b"}) {
+		t.Error(s.GetSlice("ARGSHOSTS"))
+	}
+	if !reflect.DeepEqual(s.Args(), []string{"rest"}) {
+		t.Error(s.Args())
+	}
+
+	if err := s.ParseArgs([]string{"-argsport", "7070"}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if s.GetInt("ARGSPORT") != 7070 {
+		t.Error(s.GetInt("ARGSPORT"), " != ", 7070)
+	}
+}
+
+func TestParseArgsFlagErrors(t *testing.T) {
+	s := New()
+	s.SetInt("ARGSCOUNT", 1, "count")
+
+	s.flagSet = flag.NewFlagSet("test", flag.ContinueOnError)
+	s.flagSet.SetOutput(io.Discard)
+	if err := s.ParseArgs([]string{"-unknown"}); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	s.flagSet = nil
+	err := s.ParseArgs([]string{"-argscount", "many"})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || parseErrs[0].Source != SourceFlag {
+		t.Error("expected a flag ParseError, got", err)
+	}
+}
+
+func TestParseArgsProgramFlagCollision(t *testing.T) {
+	s := New()
+	s.SetInt("port", 9090, "port")
+	s.flagSet = flag.NewFlagSet("test", flag.ContinueOnError)
+	s.flagSet.SetOutput(io.Discard)
+	s.flagSet.Int("port", 8080, "program port")
+
+	err := s.ParseArgs([]string{"-port", "7070"})
+	if err == nil || err.Error() != "flag -port is defined by both the program and settingo" {
+		t.Error("expected a collision error, got", err)
+	}
+	if s.GetInt("port") != 9090 {
+		t.Error(s.GetInt("port"), " != ", 9090)
+	}
+}
+
+func TestParseRunsUserCodeUnlocked(t *testing.T) {
+	s := New()
+	s.SetInt("UNLOCKEDPORT", 8080, "port")
+	s.SetParsed("UNLOCKEDHOST", "localhost", "host", func(val string) string {
+		return val + ":" + strconv.Itoa(s.GetInt("UNLOCKEDPORT"))
+	})
+	s.AddRules("UNLOCKEDPORT", func(val interface{}, source SourceInfo) error {
+		if s.Get("UNLOCKEDHOST") == "" {
+			return errors.New("host is not set")
+		}
+		return nil
+	})
+	s.flagSet = flag.NewFlagSet("test", flag.ContinueOnError)
+	s.flagSet.SetOutput(io.Discard)
+	s.flagSet.Usage = func() {
+		s.PrintUsage(io.Discard)
+	}
+
+	done := make(chan error)
+	go func() {
+		if err := s.ParseArgs([]string{"-help"}); err != flag.ErrHelp {
+			done <- err
+			return
+		}
+		done <- s.ParseArgs([]string{"-unlockedport", "9090", "-unlockedhost", "db"})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("parse deadlocked")
+	}
+	if s.Get("UNLOCKEDHOST") != "db:8080" {
+		t.Error(s.Get("UNLOCKEDHOST"), " != ", "db:8080")
+	}
+	if s.GetInt("UNLOCKEDPORT") != 9090 {
+		t.Error(s.GetInt("UNLOCKEDPORT"), " != ", 9090)
+	}
+}
@@ -0,0 +1,82 @@
+package settingo
+
+import "fmt"
+
+// SourceKind identifies the input a setting value was read from.
+type SourceKind int
+
+const (
+	// SourceDefault is the value given when the setting was registered.
+	SourceDefault SourceKind = iota
+	// SourceEnv is a value read from an environment variable.
+	SourceEnv
+	// SourceFlag is a value read from a command-line flag.
+	SourceFlag
+	// SourceStruct is a value or field registered through LoadStruct.
+	SourceStruct
+	// SourceFile is a value read from a configuration file.
+	SourceFile
+	// SourceCode is a value assigned by the program through Setting.Set.
+	SourceCode
+)
+
+// String returns the lowercase name of the source, e.g. "env" or "flag".
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceStruct:
+		return "struct"
+	case SourceFile:
+		return "file"
+	case SourceCode:
+		return "code"
+	}
+	return fmt.Sprintf("source(%d)", int(k))
+}
+
+// SourceInfo describes where the current value of a setting came from.
+//
+// Name is the environment variable or flag the value was read from.
+// File and Line locate the value in a configuration or dotenv file,
+// Line is 0 when the format does not report positions.
+type SourceInfo struct {
+	Kind SourceKind
+	Name string
+	File string
+	Line int
+}
+
+// String returns a short description such as "env MYAPP_PORT" or "file config.yaml:12".
+func (i SourceInfo) String() string {
+	switch {
+	case i.Kind == SourceFlag:
+		return fmt.Sprintf("%s -%s", i.Kind, i.Name)
+	case i.File != "" && i.Name != "":
+		return fmt.Sprintf("%s %s (%s)", i.Kind, i.Name, i.location())
+	case i.File != "":
+		return fmt.Sprintf("%s %s", i.Kind, i.location())
+	case i.Name != "":
+		return fmt.Sprintf("%s %s", i.Kind, i.Name)
+	}
+	return i.Kind.String()
+}
+
+func (i SourceInfo) location() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return i.File
+}
+
+// Source returns where the current value of the setting came from.
+// Unknown settings report SourceDefault.
+func (s *Settings) Source(flagName string) SourceInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.origins[s.key(flagName)]
+}
@@ -0,0 +1,73 @@
+package settingo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSourceTracksEveryInput(t *testing.T) {
+	config := writeTestFile(t, "config.yaml", "srcfile: from file\nsrcenv: from file\nnested:\n  srcnested: 1\n")
+	dotenv := writeTestFile(t, ".env", "# comment\nSRCDOTENV=from dotenv\n")
+
+	os.Setenv("SRCENV", "from env")
+	defer os.Unsetenv("SRCENV")
+
+	s := New()
+	s.SetString("SRCDEFAULT", "default", "default")
+	s.SetString("SRCFILE", "default", "file")
+	s.SetString("SRCENV", "default", "env")
+	s.SetString("SRCDOTENV", "default", "dotenv")
+	s.SetString("SRCFLAG", "default", "flag")
+	s.SetInt("NESTED.SRCNESTED", 0, "nested")
+	s.SetConfigFile("CONFIG", config, "config")
+	if err := s.LoadDotEnv(dotenv); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ParseArgs([]string{"-srcflag", "from flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		expected SourceInfo
+		str      string
+	}{
+		{"SRCDEFAULT", SourceInfo{Kind: SourceDefault}, "default"},
+		{"SRCFILE", SourceInfo{Kind: SourceFile, File: config, Line: 1}, "file " + config + ":1"},
+		{"NESTED.SRCNESTED", SourceInfo{Kind: SourceFile, File: config, Line: 4}, "file " + config + ":4"},
+		{"SRCENV", SourceInfo{Kind: SourceEnv, Name: "SRCENV"}, "env SRCENV"},
+		{"SRCDOTENV", SourceInfo{Kind: SourceEnv, Name: "SRCDOTENV", File: dotenv, Line: 2}, "env SRCDOTENV (" + dotenv + ":2)"},
+		{"SRCFLAG", SourceInfo{Kind: SourceFlag, Name: "srcflag"}, "flag -srcflag"},
+	}
+	for _, tc := range testcases {
+		got := s.Source(tc.name)
+		if got != tc.expected {
+			t.Errorf("Source(%s) = %+v, want %+v", tc.name, got, tc.expected)
+		}
+		if got.String() != tc.str {
+			t.Errorf("Source(%s).String() = %q, want %q", tc.name, got.String(), tc.str)
+		}
+	}
+}
+
+func TestSourceAfterFailedConversion(t *testing.T) {
+	os.Setenv("SRCINVALID", "abc")
+	defer os.Unsetenv("SRCINVALID")
+
+	s := New()
+	s.SetInt("SRCINVALID", 1, "invalid")
+	if err := s.ParseArgs(nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if s.Source("SRCINVALID").Kind != SourceDefault {
+		t.Error("a rejected value should not change the source", s.Source("SRCINVALID"))
+	}
+
+	type config struct {
+		SrcStruct string
+	}
+	s.LoadStruct(&config{SrcStruct: "value"})
+	if s.Source("SRCSTRUCT").Kind != SourceStruct {
+		t.Error(s.Source("SRCSTRUCT"), " != ", SourceStruct)
+	}
+}
@@ -0,0 +1,178 @@
+package settingo
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SetStrictEnv enables or disables the check for unknown environment variables.
+//
+// Every environment variable starting with the env prefix must belong to a
+// setting, so MYAPP_MAX_CONECTIONS is reported instead of never being read.
+// Unknown variables fail validation like a broken rule, naming the closest
+// settings. Strict mode needs an env prefix: without one there is no telling
+// the program's variables from USER, PATH and the rest of the environment,
+// and nothing is checked.
+func (s *Settings) SetStrictEnv(strict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strictEnv = strict
+}
+
+// checkUnknownEnv records an error for every environment variable strict mode rejects.
+// Settings of the whole command tree count as known, so that a parent does
+// not reject the variables of its subcommands.
+func (s *Settings) checkUnknownEnv() {
+	if s.envPrefix == "" {
+		return
+	}
+	known := s.treeEnvNames()
+	candidates := make([]string, 0, len(known))
+	for name := range known {
+		if strings.HasPrefix(name, s.envPrefix) {
+			candidates = append(candidates, strings.TrimPrefix(name, s.envPrefix))
+		}
+	}
+	sort.Strings(candidates)
+
+	for _, name := range s.environNames() {
+		if known[name] || !strings.HasPrefix(name, s.envPrefix) {
+			continue
+		}
+		// The prefix is left out of the comparison, it would make every name look alike.
+		suggestions := suggest(strings.TrimPrefix(name, s.envPrefix), candidates)
+		for i, suggestion := range suggestions {
+			suggestions[i] = s.envPrefix + suggestion
+		}
+		val, _ := s.lookupEnv(name)
+		s.addError(name, SourceEnv, val, unknownError("environment variable", suggestions))
+	}
+}
+
+// environNames returns the names of the process environment and the loaded dotenv files.
+func (s *Settings) environNames() []string {
+	seen := make(map[string]bool)
+	for _, entry := range os.Environ() {
+		seen[strings.SplitN(entry, "=", 2)[0]] = true
+	}
+	for name := range s.dotenv {
+		seen[name] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// treeEnvNames returns the environment variables read by s, its ancestors
+// and its subcommands. The caller holds the lock of s.
+func (s *Settings) treeEnvNames() map[string]bool {
+	known := make(map[string]bool)
+	s.addEnvNames(known)
+	for _, parent := range s.ancestors() {
+		parent.mu.RLock()
+		parent.addEnvNames(known)
+		parent.mu.RUnlock()
+	}
+	var walk func(commands []*Command)
+	walk = func(commands []*Command) {
+		for _, cmd := range commands {
+			cmd.Settings.mu.RLock()
+			cmd.Settings.addEnvNames(known)
+			children := cmd.Settings.commands
+			cmd.Settings.mu.RUnlock()
+			walk(children)
+		}
+	}
+	walk(s.commands)
+	return known
+}
+
+func (s *Settings) addEnvNames(known map[string]bool) {
+	for _, key := range s.names() {
+		known[s.envName(key)] = true
+	}
+}
+
+func unknownError(what string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return fmt.Errorf("unknown %s", what)
+	}
+	return fmt.Errorf("unknown %s, did you mean %s?", what, strings.Join(suggestions, " or "))
+}
+
+// suggest returns the candidates closest to name, at most three, when they
+// are close enough to be a likely typo.
+func suggest(name string, candidates []string) []string {
+	limit := len(name) / 4
+	if limit < 1 {
+		limit = 1
+	}
+	best := limit + 1
+	var suggestions []string
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		switch {
+		case distance < best:
+			best, suggestions = distance, []string{candidate}
+		case distance == best && len(suggestions) < 3:
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// flagSuggestion adds the closest flag names to the error the flag package
+// returns for an undefined flag.
+func flagSuggestion(fs *flag.FlagSet, err error) error {
+	const undefined = "flag provided but not defined: -"
+	if err == nil || !strings.HasPrefix(err.Error(), undefined) {
+		return err
+	}
+	var candidates []string
+	fs.VisitAll(func(f *flag.Flag) {
+		candidates = append(candidates, f.Name)
+	})
+	suggestions := suggest(strings.TrimPrefix(err.Error(), undefined), candidates)
+	if len(suggestions) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w, did you mean -%s?", err, strings.Join(suggestions, " or -"))
+}
@@ -0,0 +1,108 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"port", "port", 0},
+		{"prot", "port", 2},
+		{"MAX_CONECTIONS", "MAX_CONNECTIONS", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b); got != c.distance {
+			t.Error(c.a, " ", c.b, ": ", got, " != ", c.distance)
+		}
+	}
+}
+
+func TestStrictEnvWithPrefix(t *testing.T) {
+	os.Setenv("STRICTAPP_MAX_CONECTIONS", "200")
+	os.Setenv("STRICTAPP_COLOUR", "red")
+	os.Setenv("STRICTAPP_PORT", "9000")
+	defer os.Unsetenv("STRICTAPP_MAX_CONECTIONS")
+	defer os.Unsetenv("STRICTAPP_COLOUR")
+	defer os.Unsetenv("STRICTAPP_PORT")
+
+	s := New(WithEnvPrefix("STRICTAPP_"), WithStrictEnv())
+	s.SetInt("max_connections", 100, "connections")
+	s.SetInt("port", 8080, "port")
+
+	err := s.ParseArgs(nil)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 2 {
+		t.Fatal(len(parseErrs), " != ", 2, err)
+	}
+	if parseErrs[0].Name != "STRICTAPP_COLOUR" || strings.Contains(parseErrs[0].Error(), "did you mean") {
+		t.Error(parseErrs[0])
+	}
+	if parseErrs[1].Name != "STRICTAPP_MAX_CONECTIONS" || !strings.Contains(parseErrs[1].Error(), "did you mean STRICTAPP_MAX_CONNECTIONS?") {
+		t.Error(parseErrs[1])
+	}
+	if got := s.GetInt("port"); got != 9000 {
+		t.Error(got, " != ", 9000)
+	}
+
+	lenient := New(WithEnvPrefix("STRICTAPP_"))
+	lenient.SetInt("port", 8080, "port")
+	if err := lenient.ParseArgs(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrictEnvWithoutPrefix(t *testing.T) {
+	os.Setenv("STRICTTIMOUT", "5")
+	t.Setenv("USER", "someone")
+	defer os.Unsetenv("STRICTTIMOUT")
+
+	s := New(WithStrictEnv())
+	s.SetInt("stricttimeout", 1, "timeout")
+	s.SetString("users", "", "users")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Error("expected no check without a prefix, got", err)
+	}
+}
+
+func TestStrictEnvCommands(t *testing.T) {
+	os.Setenv("TREEAPP_SERVE_PORT", "9000")
+	defer os.Unsetenv("TREEAPP_SERVE_PORT")
+
+	root := New(WithEnvPrefix("TREEAPP_"), WithStrictEnv())
+	serve := root.AddCommand(&Command{Name: "serve", Run: func(args []string) error { return nil }})
+	serve.Settings.SetInt("serve_port", 8080, "port")
+	if err := root.Execute([]string{"serve"}); err != nil {
+		t.Error(err)
+	}
+	if got := serve.Settings.GetInt("serve_port"); got != 9000 {
+		t.Error(got, " != ", 9000)
+	}
+}
+
+func TestUnknownFlagSuggestion(t *testing.T) {
+	s := New()
+	s.SetInt("port", 8080, "port")
+	err := s.ParseArgs([]string{"-prot=1"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean -port?") {
+		t.Error("expected a suggestion, got", err)
+	}
+
+	root := New()
+	root.AddCommand(&Command{Name: "serve"})
+	err = root.Execute([]string{"serv"})
+	if err == nil || !strings.Contains(err.Error(), `did you mean serve?`) {
+		t.Error("expected a suggestion, got", err)
+	}
+}
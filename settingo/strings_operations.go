@@ -89,17 +89,32 @@ func parseKeyValue(s string) (string, []string, bool) {
 //	// }
 //	// "Settingo: Unable to parse line, discarded: invalid-item" will be printed to console.
 func ParseLineToMap(s string) map[string][]string {
+	parsed, discarded := parseLine(s)
+	for _, item := range discarded {
+		fmt.Println("Settingo: Unable to parse line, discarded:", item)
+	}
+	return parsed
+}
+
+// parseLine is the silent counterpart of ParseLineToMap.
+//
+// It returns the parsed map together with every non-empty item that could not
+// be parsed, leaving it to the caller to decide how to report them.
+func parseLine(s string) (map[string][]string, []string) {
 	parsed := make(map[string][]string)
+	discarded := []string{}
 	items := strings.Split(s, ITEM_DELIMITER)
 	for _, item := range items {
 		key, values, err := parseKeyValue(item)
 		if err {
-			fmt.Println("Settingo: Unable to parse line, discarded:", item)
+			if item != "" {
+				discarded = append(discarded, item)
+			}
 			continue
 		}
 		parsed[key] = values
 	}
-	return parsed
+	return parsed, discarded
 }
 
 // ParseMapToLine converts a map[string][]string into a line string representation.
@@ -0,0 +1,109 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStructTags(t *testing.T) {
+	type database struct {
+		URL string `env:"TAGS_DATABASE_URL"`
+	}
+	type config struct {
+		DBURL    string   `settingo:"Database url" env:"TAGS_DB_URL" flag:"db-url"`
+		Port     int      `default:"8080"`
+		Workers  int      `default:"4"`
+		Hosts    []string `sep:"|" default:"a|b"`
+		Password string   `secret:"true"`
+		Internal string   `settingo:"-"`
+		DB       database `flag:"tagsdb"`
+	}
+	cfg := &config{Workers: 2, Internal: "keep"}
+
+	os.Setenv("TAGS_DB_URL", "postgres://db")
+	os.Setenv("TAGS_DATABASE_URL", "postgres://nested")
+	os.Setenv("HOSTS", "x|y|z")
+	os.Setenv("INTERNAL", "changed")
+	defer os.Unsetenv("TAGS_DB_URL")
+	defer os.Unsetenv("TAGS_DATABASE_URL")
+	defer os.Unsetenv("HOSTS")
+	defer os.Unsetenv("INTERNAL")
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	s := New()
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBURL != "postgres://db" {
+		t.Error(cfg.DBURL, " != ", "postgres://db")
+	}
+	if cfg.DB.URL != "postgres://nested" {
+		t.Error(cfg.DB.URL, " != ", "postgres://nested")
+	}
+	if cfg.Port != 8080 {
+		t.Error(cfg.Port, " != ", 8080)
+	}
+	if cfg.Workers != 2 {
+		t.Error(cfg.Workers, " != ", 2)
+	}
+	if strings.Join(cfg.Hosts, ",") != "x,y,z" {
+		t.Error(cfg.Hosts, " != ", "[x y z]")
+	}
+	if cfg.Internal != "keep" {
+		t.Error(cfg.Internal, " != ", "keep")
+	}
+	if !s.secrets["password"] {
+		t.Error("password should be secret")
+	}
+	if _, found := s.msg["internal"]; found {
+		t.Error("skipped field should not be registered")
+	}
+	if got := s.msg["db-url"]; got != "Database url" {
+		t.Error(got, " != ", "Database url")
+	}
+
+	os.Args = []string{"settingo", "-db-url=mysql://db", "-tagsdb.url=mysql://nested"}
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBURL != "mysql://db" {
+		t.Error(cfg.DBURL, " != ", "mysql://db")
+	}
+	if cfg.DB.URL != "mysql://nested" {
+		t.Error(cfg.DB.URL, " != ", "mysql://nested")
+	}
+}
+
+func TestStructTagsRequiredAndInvalidDefault(t *testing.T) {
+	type config struct {
+		TagToken string `required:"true"`
+		TagLimit int    `default:"many"`
+	}
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	err := New().ParseToE(&config{})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	if len(parseErrs) != 2 {
+		t.Error(len(parseErrs), " != ", 2, err)
+	}
+
+	os.Setenv("TAGTOKEN", "secret")
+	defer os.Unsetenv("TAGTOKEN")
+	type valid struct {
+		TagToken string `required:"true"`
+	}
+	if err := New().ParseToE(&valid{}); err != nil {
+		t.Error(err)
+	}
+}
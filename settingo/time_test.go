@@ -0,0 +1,94 @@
+package settingo
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDurationSetting(t *testing.T) {
+	s := New()
+	s.SetDuration("timeout", 30*time.Second, "timeout")
+	s.SetDuration("interval", time.Minute, "interval")
+
+	os.Setenv("TIMEOUT", "1m30s")
+	defer os.Unsetenv("TIMEOUT")
+
+	if err := s.ParseArgs([]string{"-interval=250ms"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetDuration("timeout"); got != 90*time.Second {
+		t.Error(got, " != ", 90*time.Second)
+	}
+	if got := s.GetDuration("interval"); got != 250*time.Millisecond {
+		t.Error(got, " != ", 250*time.Millisecond)
+	}
+
+	err := s.ParseArgs([]string{"-interval=30"})
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 {
+		t.Fatalf("expected one ParseError, got %v", err)
+	}
+}
+
+func TestTimeSetting(t *testing.T) {
+	s := New()
+	s.SetTime("start", time.Time{}, "start")
+	s.SetTime("since", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "since", "2006-01-02", time.RFC3339)
+
+	if err := s.ParseArgs([]string{"-start=2024-03-01T10:00:00Z", "-since=2024-02-15"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.GetTime("start"), time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Error(got, " != ", want)
+	}
+	if got, want := s.GetTime("since"), time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Error(got, " != ", want)
+	}
+
+	if err := s.ParseArgs([]string{"-since=2024-02-15T08:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.GetTime("since"), time.Date(2024, 2, 15, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Error(got, " != ", want)
+	}
+
+	if err := s.ParseArgs([]string{"-start=yesterday"}); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
+
+func TestTimeStructFields(t *testing.T) {
+	type config struct {
+		TimeTimeout time.Duration
+		TimeStart   time.Time
+		TimeDay     time.Time `layout:"2006-01-02"`
+	}
+	cfg := &config{TimeTimeout: 5 * time.Second}
+
+	os.Setenv("TIMETIMEOUT", "2m")
+	os.Setenv("TIMESTART", "2024-03-01T10:00:00Z")
+	os.Setenv("TIMEDAY", "2024-03-02")
+	defer os.Unsetenv("TIMETIMEOUT")
+	defer os.Unsetenv("TIMESTART")
+	defer os.Unsetenv("TIMEDAY")
+
+	args := os.Args
+	os.Args = []string{"settingo"}
+	defer func() { os.Args = args }()
+
+	s := New()
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TimeTimeout != 2*time.Minute {
+		t.Error(cfg.TimeTimeout, " != ", 2*time.Minute)
+	}
+	if want := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); !cfg.TimeStart.Equal(want) {
+		t.Error(cfg.TimeStart, " != ", want)
+	}
+	if want := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC); !cfg.TimeDay.Equal(want) {
+		t.Error(cfg.TimeDay, " != ", want)
+	}
+}
@@ -0,0 +1,287 @@
+package settingo
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"golang.org/x/term"
+)
+
+// UsageEntry describes one setting in the help output.
+type UsageEntry struct {
+	Flag    string
+	Aliases []string
+	Env     string
+	Type    string
+	Default string
+	Help    string
+	Group   string
+}
+
+// Description returns the help message followed by the default and the
+// environment variable, e.g. `Port to listen on (default 8080, env PORT)`.
+func (e UsageEntry) Description() string {
+	var details []string
+	if e.Default != "" {
+		if e.Type == "string" {
+			details = append(details, "default "+strconv.Quote(e.Default))
+		} else {
+			details = append(details, "default "+e.Default)
+		}
+	}
+	if e.Env != "" {
+		details = append(details, "env "+e.Env)
+	}
+	if len(details) == 0 {
+		return e.Help
+	}
+	return strings.TrimSpace(e.Help + " (" + strings.Join(details, ", ") + ")")
+}
+
+// UsageGroup is a named group of settings, Name is empty for settings without a group.
+type UsageGroup struct {
+	Name     string
+	Settings []UsageEntry
+}
+
+// UsageCommand is a subcommand listed in the help output.
+type UsageCommand struct {
+	Name string
+	Help string
+}
+
+// UsageData is passed to the usage template.
+type UsageData struct {
+	Program  string
+	Help     string
+	Groups   []UsageGroup
+	Commands []UsageCommand
+	Footer   string
+}
+
+const defaultUsageTemplate = `{{define "entry"}}  {{.Flag}}{{range .Aliases}}, {{.}}{{end}}{{if ne .Type "bool"}} {{.Type}}{{end}}
+{{wrap 8 .Description}}
+{{end}}Usage of {{.Program}}:{{if .Commands}} [flags] command [arguments]{{end}}
+{{if .Help}}{{wrap 2 .Help}}
+{{end}}{{range .Groups}}
+{{if .Name}}{{.Name}}{{else}}Flags{{end}}:
+{{range .Settings}}{{template "entry" .}}{{end}}{{end}}{{if .Commands}}
+Commands:
+{{range .Commands}}{{printf "  %-*s  %s" (commandWidth $.Commands) .Name .Help}}
+{{end}}{{end}}
+{{wrap 0 .Footer}}
+`
+
+// usageFuncs are the functions available to usage templates:
+// wrap indents text and wraps it to the terminal width, see usageWidth.
+var usageFuncs = template.FuncMap{
+	"wrap":         wrap,
+	"commandWidth": commandWidth,
+}
+
+// SetGroup puts settings under a heading in the help output, e.g. "Database".
+func (s *Settings) SetGroup(group string, flagNames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, flagName := range flagNames {
+		s.groups[s.key(flagName)] = group
+	}
+}
+
+// SetUsageTemplate replaces the help output with a text/template executed
+// with a UsageData. The template can use the wrap function, {{wrap 8 .Help}}
+// indents the help by eight spaces and wraps it to the terminal width.
+func (s *Settings) SetUsageTemplate(text string) error {
+	tmpl, err := template.New("usage").Funcs(usageFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usageTemplate = tmpl
+	return nil
+}
+
+// PrintUsage writes the help output, the same text -help prints.
+func (s *Settings) PrintUsage(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ancestors := s.ancestors()
+	for _, parent := range ancestors {
+		parent.mu.RLock()
+		defer parent.mu.RUnlock()
+	}
+	return s.renderUsage(w)
+}
+
+// printUsage is the usage function of the flag sets settingo parses with.
+// It is called during parsing, with s and its ancestors read locked. Flags the
+// program defined on fs itself, e.g. on flag.CommandLine, are listed after
+// the settings.
+func (s *Settings) printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	if err := s.renderUsage(w); err != nil {
+		fmt.Fprintln(w, err)
+	}
+	other := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	fs.VisitAll(func(f *flag.Flag) {
+		switch f.Value.(type) {
+		case *flagValue, *negatedValue:
+			return
+		}
+		other.Var(f.Value, f.Name, f.Usage)
+		other.Lookup(f.Name).DefValue = f.DefValue
+	})
+	hasOther := false
+	other.VisitAll(func(*flag.Flag) { hasOther = true })
+	if hasOther {
+		fmt.Fprintln(w, "\nOther flags:")
+		other.SetOutput(w)
+		other.PrintDefaults()
+	}
+}
+
+// usageFunc returns printUsage as the usage function of fs.
+func (s *Settings) usageFunc(fs *flag.FlagSet) func() {
+	owner := s.owner()
+	return func() { owner.printUsage(fs) }
+}
+
+func (s *Settings) renderUsage(w io.Writer) error {
+	tmpl := s.usageTemplate
+	if tmpl == nil {
+		tmpl = template.Must(template.New("usage").Funcs(usageFuncs).Parse(defaultUsageTemplate))
+	}
+	return tmpl.Execute(w, s.usageData())
+}
+
+// usageData collects the settings of s and the persistent settings of its
+// parent commands. The caller holds the locks.
+func (s *Settings) usageData() UsageData {
+	data := UsageData{Program: s.programName(), Help: s.help()}
+	entries := s.usageEntries(nil)
+	for _, parent := range s.ancestors() {
+		entries = append(entries, parent.usageEntries(parent.persistent)...)
+	}
+
+	groups := make(map[string]*UsageGroup)
+	var names []string
+	hasBool := false
+	for _, entry := range entries {
+		group, found := groups[entry.Group]
+		if !found {
+			group = &UsageGroup{Name: entry.Group}
+			groups[entry.Group] = group
+			names = append(names, entry.Group)
+		}
+		group.Settings = append(group.Settings, entry)
+		hasBool = hasBool || entry.Type == "bool"
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data.Groups = append(data.Groups, *groups[name])
+	}
+	for _, cmd := range s.commands {
+		data.Commands = append(data.Commands, UsageCommand{Name: cmd.Name, Help: cmd.Help})
+	}
+
+	data.Footer = "Flags take precedence over environment variables, which take precedence over configuration files and defaults."
+	if hasBool {
+		data.Footer += " Boolean settings accept true/false, yes/no, on/off and 1/0, the flag alone means true and -no-<name> means false."
+	}
+	return data
+}
+
+// usageEntries describes the settings of s, or only those in subset when it is not nil.
+func (s *Settings) usageEntries(subset map[string]bool) []UsageEntry {
+	aliases := make(map[string][]string)
+	for alias, key := range s.aliases {
+		aliases[key] = append(aliases[key], "-"+alias)
+	}
+
+	var entries []UsageEntry
+	for _, key := range s.names() {
+		if subset != nil && !subset[key] {
+			continue
+		}
+		sort.Strings(aliases[key])
+		entry := UsageEntry{
+			Flag:    "-" + key,
+			Aliases: aliases[key],
+			Env:     s.envName(key),
+			Type:    s.typeName(key),
+			Help:    s.msg[key],
+			Group:   s.groups[key],
+		}
+		if !s.reads(SourceEnv) {
+			entry.Env = ""
+		}
+		if def, found := s.defaults[key]; found {
+			entry.Default = s.format(key, def)
+			if s.secrets[key] && entry.Default != "" {
+				entry.Default = redacted
+			}
+		}
+		if entry.Default == "false" || entry.Default == "0" {
+			entry.Default = ""
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// terminalWidth returns the width of the terminal on stderr, where help output is written.
+var terminalWidth = func() (int, error) {
+	width, _, err := term.GetSize(int(os.Stderr.Fd()))
+	return width, err
+}
+
+// usageWidth is the width help output is wrapped to: the width of the
+// terminal, COLUMNS when stderr is not a terminal, or 80.
+func usageWidth() int {
+	if width, err := terminalWidth(); err == nil && width > 20 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 20 {
+		return columns
+	}
+	return 80
+}
+
+// wrap indents text by indent spaces and breaks it into lines that fit the usage width.
+func wrap(indent int, text string) string {
+	width := usageWidth() - indent
+	prefix := strings.Repeat(" ", indent)
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, prefix+line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, prefix+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func commandWidth(commands []UsageCommand) int {
+	width := 0
+	for _, cmd := range commands {
+		if len(cmd.Name) > width {
+			width = len(cmd.Name)
+		}
+	}
+	return width
+}
@@ -0,0 +1,164 @@
+package settingo
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStderr returns what fn writes to os.Stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	return captureOutput(t, &os.Stderr, fn)
+}
+
+// captureOutput returns what fn writes to file, os.Stdout or os.Stderr.
+func captureOutput(t *testing.T, file **os.File, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := *file
+	*file = w
+	defer func() { *file = original }()
+	fn()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestUsageGroups(t *testing.T) {
+	s := New()
+	s.Set("host", "localhost", "Host to listen on")
+	s.SetInt("db-port", 5432, "Database port")
+	s.SetSecret("db-password", "hunter2", "Database password")
+	s.SetGroup("Database", "db-port", "db-password")
+
+	var out bytes.Buffer
+	if err := s.PrintUsage(&out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"Flags:\n  -host string\n",
+		`Host to listen on (default "localhost", env HOST)`,
+		"Database:\n",
+		"Database port (default 5432, env DB-PORT)",
+		"Flags take precedence over environment variables",
+	} {
+		if !strings.Contains(got, want) {
+			t.Error(got, " does not contain ", want)
+		}
+	}
+	if strings.Contains(got, "hunter2") {
+		t.Error(got, " contains the secret default")
+	}
+	if strings.Index(got, "Flags:") > strings.Index(got, "Database:") {
+		t.Error(got, " does not list ungrouped settings first")
+	}
+}
+
+func TestUsageGroupTag(t *testing.T) {
+	type Config struct {
+		Host string `settingo:"Host" group:"Server"`
+	}
+	s := New()
+	s.LoadStruct(&Config{})
+
+	var out bytes.Buffer
+	s.PrintUsage(&out)
+	if !strings.Contains(out.String(), "Server:\n  -host string\n") {
+		t.Error(out.String(), " does not contain the Server group")
+	}
+}
+
+func TestUsageWrap(t *testing.T) {
+	defer func(fn func() (int, error)) { terminalWidth = fn }(terminalWidth)
+	terminalWidth = func() (int, error) { return 0, errors.New("not a terminal") }
+	t.Setenv("COLUMNS", "40")
+	got := wrap(4, "the quick brown fox jumps over the lazy dog and keeps on running")
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > 40 || !strings.HasPrefix(line, "    ") {
+			t.Error(line, " != line of at most 40 characters indented by 4")
+		}
+	}
+
+	terminalWidth = func() (int, error) { return 30, nil }
+	if got := usageWidth(); got != 30 {
+		t.Error(got, " != ", 30)
+	}
+}
+
+func TestUsageTemplate(t *testing.T) {
+	s := New()
+	s.SetBool("verbose", false, "Verbose output")
+	if err := s.SetUsageTemplate("{{range .Groups}}{{range .Settings}}{{.Flag}}={{.Env}};{{end}}{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	s.PrintUsage(&out)
+	if out.String() != "-verbose=VERBOSE;" {
+		t.Error(out.String(), " != ", "-verbose=VERBOSE;")
+	}
+
+	if err := s.SetUsageTemplate("{{.Missing"); err == nil {
+		t.Error("invalid template accepted")
+	}
+}
+
+func TestUsageHelpFlag(t *testing.T) {
+	s := New()
+	s.Set("host", "localhost", "Host to listen on")
+	s.SetGroup("Server", "host")
+	out := captureStderr(t, func() {
+		if err := s.ParseArgs([]string{"-help"}); err != flag.ErrHelp {
+			t.Error(err, " != ", flag.ErrHelp)
+		}
+	})
+	for _, want := range []string{"Server:\n  -host string\n", "env HOST"} {
+		if !strings.Contains(out, want) {
+			t.Error(out, " does not contain ", want)
+		}
+	}
+}
+
+func TestUsageKeepsProgramFlags(t *testing.T) {
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.Bool("dry-run", false, "Only print what would be done")
+	s := New(WithFlagSet(fs))
+	s.Set("host", "localhost", "Host to listen on")
+	s.ParseArgs([]string{"-help"})
+	for _, want := range []string{"env HOST", "Other flags:", "Only print what would be done"} {
+		if !strings.Contains(out.String(), want) {
+			t.Error(out.String(), " does not contain ", want)
+		}
+	}
+
+	fs = flag.NewFlagSet("tool", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	out.Reset()
+	s = New(WithFlagSet(fs))
+	fs.Usage = func() { out.WriteString("custom usage") }
+	s.Set("host", "localhost", "Host to listen on")
+	s.ParseArgs([]string{"-help"})
+	if out.String() != "custom usage" {
+		t.Error(out.String(), " != ", "custom usage")
+	}
+}
+
+func TestCommandLineUsage(t *testing.T) {
+	var out bytes.Buffer
+	flag.CommandLine.SetOutput(&out)
+	defer flag.CommandLine.SetOutput(nil)
+	SETTINGS.Set("usagehost", "localhost", "Host to listen on")
+
+	flag.Usage()
+	if !strings.Contains(out.String(), "Host to listen on") {
+		t.Error(out.String(), " does not contain ", "Host to listen on")
+	}
+}
@@ -0,0 +1,114 @@
+package settingo
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var (
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	urlType             = reflect.TypeOf((*url.URL)(nil))
+)
+
+// isValueField reports whether struct fields of type typ are converted by
+// their own Set or UnmarshalText method instead of by settingo.
+//
+// time.Time is left to the time support so that its layouts apply, and
+// *url.URL is accepted as it is the common case without a text method.
+func isValueField(typ reflect.Type) bool {
+	if typ == timeType {
+		return false
+	}
+	if typ == urlType {
+		return true
+	}
+	for _, candidate := range []reflect.Type{typ, reflect.PtrTo(typ)} {
+		if candidate.Kind() != reflect.Ptr {
+			continue
+		}
+		if candidate.Implements(flagValueType) || candidate.Implements(textUnmarshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue stores the value of a struct field handled by isValueField.
+//
+// val is a private, addressable copy of the field, so parsing input never
+// touches the struct until UpdateStruct copies the result back.
+type fieldValue struct {
+	val reflect.Value
+}
+
+func newFieldValue(field reflect.Value) *fieldValue {
+	val := reflect.New(field.Type()).Elem()
+	val.Set(field)
+	return &fieldValue{val: val}
+}
+
+// clone returns a shallow copy, enough to keep defaults apart from parsed values
+// since Set never modifies the value a pointer field refers to.
+func (f *fieldValue) clone() *fieldValue {
+	return newFieldValue(f.val)
+}
+
+// target returns the pointer whose methods convert the value.
+func (f *fieldValue) target() interface{} {
+	if f.val.Kind() == reflect.Ptr {
+		return f.val.Interface()
+	}
+	return f.val.Addr().Interface()
+}
+
+func (f *fieldValue) Set(raw string) error {
+	if f.val.Kind() == reflect.Ptr {
+		f.val.Set(reflect.New(f.val.Type().Elem()))
+	}
+	switch target := f.target().(type) {
+	case flag.Value:
+		return target.Set(raw)
+	case encoding.TextUnmarshaler:
+		return target.UnmarshalText([]byte(raw))
+	case *url.URL:
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		*target = *parsed
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s", f.val.Type())
+}
+
+func (f *fieldValue) String() string {
+	if f.val.Kind() == reflect.Ptr && f.val.IsNil() {
+		return ""
+	}
+	switch target := f.target().(type) {
+	case flag.Value:
+		return target.String()
+	case encoding.TextMarshaler:
+		text, err := target.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	case fmt.Stringer:
+		return target.String()
+	}
+	return fmt.Sprint(f.val.Interface())
+}
+
+// plainValue returns val with fieldValue unwrapped to the field's own type,
+// which is what rules and OnChange callbacks receive.
+func plainValue(val interface{}) interface{} {
+	if fieldValue, ok := val.(*fieldValue); ok {
+		return fieldValue.val.Interface()
+	}
+	return val
+}
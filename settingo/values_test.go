@@ -0,0 +1,110 @@
+package settingo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info", "error"}[l]), nil
+}
+
+type testHosts []string
+
+func (h *testHosts) String() string {
+	return strings.Join(*h, ";")
+}
+
+func (h *testHosts) Set(value string) error {
+	*h = strings.Split(value, ";")
+	return nil
+}
+
+func TestValueStructFields(t *testing.T) {
+	type config struct {
+		ValueIP       net.IP
+		ValueEndpoint *url.URL
+		ValueLevel    testLevel
+		ValueHosts    testHosts
+	}
+	endpoint, _ := url.Parse("http://localhost:8080")
+	cfg := &config{ValueIP: net.ParseIP("127.0.0.1"), ValueEndpoint: endpoint, ValueLevel: 1}
+
+	os.Setenv("VALUEIP", "10.0.0.1")
+	os.Setenv("VALUELEVEL", "error")
+	defer os.Unsetenv("VALUEIP")
+	defer os.Unsetenv("VALUELEVEL")
+
+	args := os.Args
+	os.Args = []string{"settingo", "-valueendpoint=https://example.com/api", "-valuehosts=a;b"}
+	defer func() { os.Args = args }()
+
+	s := New()
+	if err := s.ParseToE(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.ValueIP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Error(cfg.ValueIP, " != ", "10.0.0.1")
+	}
+	if cfg.ValueEndpoint.String() != "https://example.com/api" {
+		t.Error(cfg.ValueEndpoint, " != ", "https://example.com/api")
+	}
+	if endpoint.String() != "http://localhost:8080" {
+		t.Error("default url was modified", endpoint)
+	}
+	if cfg.ValueLevel != 2 {
+		t.Error(cfg.ValueLevel, " != ", 2)
+	}
+	if strings.Join(cfg.ValueHosts, ",") != "a,b" {
+		t.Error(cfg.ValueHosts, " != ", "[a b]")
+	}
+	if got := s.typeName("valueip"); got != "net.IP" {
+		t.Error(got, " != ", "net.IP")
+	}
+}
+
+func TestValueStructFieldErrors(t *testing.T) {
+	type config struct {
+		ValueLevel testLevel `validate:"oneof=debug info"`
+	}
+	cfg := &config{ValueLevel: 1}
+
+	args := os.Args
+	os.Args = []string{"settingo", "-valuelevel=loud"}
+	defer func() { os.Args = args }()
+
+	err := New().ParseToE(cfg)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 {
+		t.Fatalf("expected one ParseError, got %v", err)
+	}
+	if cfg.ValueLevel != 1 {
+		t.Error(cfg.ValueLevel, " != ", 1)
+	}
+
+	os.Args = []string{"settingo", "-valuelevel=error"}
+	if err := New().ParseToE(cfg); err == nil {
+		t.Error("expected the oneof rule to reject error")
+	}
+}
@@ -0,0 +1,314 @@
+package settingo
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultWatchInterval is how often Watch checks files when no interval is configured.
+const defaultWatchInterval = 2 * time.Second
+
+// OnChange registers fn to be called when Reload changes the value of a setting.
+//
+// fn receives the old and the new value with the type the setting was
+// registered with. Callbacks run after the new values are in place, outside
+// of any lock, so they may read other settings.
+func (s *Settings) OnChange(flagName string, fn func(old, new interface{})) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.key(flagName)
+	s.callbacks[key] = append(s.callbacks[key], fn)
+}
+
+// OnReloadError registers fn to receive the errors of reloads triggered by Watch.
+// Without it, errors are printed to stderr.
+func (s *Settings) OnReloadError(fn func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadErrors = fn
+}
+
+// Reload reads the configuration files, dotenv files and environment again,
+// reapplies the command line of the last parse and swaps in the new values.
+//
+// The new values are parsed and validated on a copy of the registry first.
+// When that fails the current values are kept and the errors are returned,
+// otherwise all values are replaced at once and the OnChange callbacks of the
+// settings that changed are called. Reloads run one at a time, and a value
+// set while a reload parses is kept rather than replaced by an older one.
+func (s *Settings) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.RLock()
+	staged := s.staging()
+	staged.reparse()
+	staged.validate()
+	parsedFrom := s.values()
+	s.mu.RUnlock()
+	if err := staged.takeErrors(); err != nil {
+		return err
+	}
+	s.swap(staged, parsedFrom)
+	return nil
+}
+
+// stage returns a copy of the registry without values. Registration data is
+// shared, values are not, so the caller holds the read lock until it is done
+// with the copy.
+func (s *Settings) stage() *Settings {
+	staged := new(Settings)
+	*staged = *s
+	staged.mu = new(sync.RWMutex)
+	staged.reloadMu = new(sync.Mutex)
+	staged.stagedFrom = s.owner()
+	staged.errs = nil
+	staged.resetValues()
+	return staged
+}
+
+// staging returns a copy of the registry with every value reset to its default.
+func (s *Settings) staging() *Settings {
+	staged := s.stage()
+	staged.dotenv = nil
+	for key, val := range s.defaults {
+		staged.setValue(key, copyValue(val))
+		staged.origins[key] = SourceInfo{Kind: SourceDefault}
+		if s.fromStruct[key] {
+			staged.origins[key] = SourceInfo{Kind: SourceStruct}
+		}
+	}
+	return staged
+}
+
+// snapshot returns a copy of the registry holding a copy of every current value.
+func (s *Settings) snapshot() *Settings {
+	staged := s.stage()
+	staged.errs = append(ParseErrors(nil), s.errs...)
+	staged.cmdline = make(map[string]flagInput, len(s.cmdline))
+	for key, input := range s.cmdline {
+		staged.cmdline[key] = input
+	}
+	for _, key := range s.names() {
+		val, _ := s.value(key)
+		staged.setValue(key, copyValue(val))
+		staged.origins[key] = s.origins[key]
+	}
+	return staged
+}
+
+// values returns a copy of the current value of every setting.
+func (s *Settings) values() map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, key := range s.names() {
+		val, _ := s.value(key)
+		values[key] = copyValue(val)
+	}
+	return values
+}
+
+// reparse applies every source except the command line itself, using the
+// flags recorded by the last parse instead.
+func (s *Settings) reparse() {
+	for _, path := range s.files {
+		if err := s.loadFile(path); err != nil {
+			s.addError(path, SourceFile, path, err)
+		}
+	}
+	if s.reads(SourceFile) {
+		s.handleFileInput(s.argv)
+	}
+	if len(s.dotenvPaths) > 0 {
+		if err := s.readDotEnv(s.dotenvPaths); err != nil {
+			s.addError(s.dotenvPaths[0], SourceEnv, "", err)
+		}
+	}
+	if s.reads(SourceEnv) {
+		s.handleOSInput()
+	}
+	if !s.reads(SourceFlag) {
+		return
+	}
+	for _, key := range s.names() {
+		input, found := s.cmdline[key]
+		if !found {
+			continue
+		}
+		if err := s.setFromString(key, input.value); err != nil {
+			s.addError(key, SourceFlag, input.value, err)
+			if !applied(err) {
+				continue
+			}
+		}
+		s.origins[key] = SourceInfo{Kind: SourceFlag, Name: input.name}
+	}
+}
+
+// setValue stores val, a value of the type the setting was registered with.
+func (s *Settings) setValue(key string, val interface{}) {
+	switch typed := val.(type) {
+	case string:
+		s.VarString[key] = typed
+	case int:
+		s.VarInt[key] = typed
+	case bool:
+		s.VarBool[key] = typed
+	case map[string][]string:
+		s.VarMap[key] = typed
+	case []string:
+		s.VarSlice[key] = typed
+	case float64:
+		s.VarFloat[key] = typed
+	case int64:
+		s.VarInt64[key] = typed
+	case uint64:
+		s.VarUint[key] = typed
+	case time.Duration:
+		s.VarDuration[key] = typed
+	case time.Time:
+		s.VarTime[key] = typed
+	case flag.Value:
+		s.VarValue[key] = typed
+	}
+}
+
+// settingChange is a setting whose value was changed by a reload.
+type settingChange struct {
+	key      string
+	old, new interface{}
+}
+
+// swap replaces the values of s with those of staged and calls the callbacks of changed settings.
+// parsedFrom holds the values staged was parsed from. Settings registered or set
+// since then keep their values.
+func (s *Settings) swap(staged *Settings, parsedFrom map[string]interface{}) {
+	callbacks := make(map[string][]func(old, new interface{}))
+	s.mu.Lock()
+	changes := s.replaceValues(staged, parsedFrom)
+	for _, change := range changes {
+		callbacks[change.key] = s.callbacks[change.key]
+	}
+	s.dotenv = staged.dotenv
+	s.configFile = staged.configFile
+	s.mu.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range callbacks[change.key] {
+			fn(change.old, change.new)
+		}
+	}
+}
+
+// commit stores the values and the command line that were parsed into staged,
+// a snapshot of s. parsedFrom holds the values of s the snapshot was taken of.
+func (s *Settings) commit(staged *Settings, parsedFrom map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replaceValues(staged, parsedFrom)
+	s.errs = staged.errs
+	s.args = staged.args
+	s.argv = staged.argv
+	s.cmdline = staged.cmdline
+	s.configFile = staged.configFile
+}
+
+// replaceValues replaces the values of s with those of staged and returns the
+// settings that changed. parsedFrom holds the values staged was parsed from.
+// Settings registered or set since then keep their values. The caller holds the lock.
+func (s *Settings) replaceValues(staged *Settings, parsedFrom map[string]interface{}) []settingChange {
+	changes := []settingChange{}
+	for _, key := range staged.names() {
+		previous, _ := s.value(key)
+		before, found := parsedFrom[key]
+		if !found || !reflect.DeepEqual(plainValue(previous), plainValue(before)) {
+			continue
+		}
+		next, _ := staged.value(key)
+		if !reflect.DeepEqual(plainValue(previous), plainValue(next)) {
+			changes = append(changes, settingChange{key: key, old: plainValue(previous), new: plainValue(next)})
+		}
+		s.setValue(key, next)
+		s.origins[key] = staged.origins[key]
+	}
+	return changes
+}
+
+// Watch reloads the settings on SIGHUP and whenever one of the configuration
+// or dotenv files is modified, until ctx is done.
+//
+// Files are checked for modifications every watch interval, see WithWatchInterval.
+// Errors of a reload are passed to the OnReloadError handler and the previous
+// values stay in place. Watch returns the error of ctx.
+func (s *Settings) Watch(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	interval := s.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	modified := s.watchedModTimes()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-hangup:
+			s.watchReload()
+			modified = s.watchedModTimes()
+		case <-ticker.C:
+			current := s.watchedModTimes()
+			if !reflect.DeepEqual(current, modified) {
+				modified = current
+				s.watchReload()
+			}
+		}
+	}
+}
+
+func (s *Settings) watchReload() {
+	err := s.Reload()
+	if err == nil {
+		return
+	}
+	s.mu.RLock()
+	handler := s.reloadErrors
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "settingo: reload failed:", err)
+}
+
+// watchedModTimes returns the modification time of every file Reload reads.
+// Missing files are included with a zero time, so their creation is noticed.
+func (s *Settings) watchedModTimes() map[string]time.Time {
+	s.mu.RLock()
+	paths := append(append([]string{}, s.files...), s.dotenvPaths...)
+	if s.configFile != "" {
+		paths = append(paths, s.configFile)
+	}
+	s.mu.RUnlock()
+
+	modified := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			modified[path] = time.Time{}
+			continue
+		}
+		modified[path] = info.ModTime()
+	}
+	return modified
+}
@@ -0,0 +1,208 @@
+package settingo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReloadSwapsValuesAndCallsOnChange(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", "watchlevel: info\nwatchrate: 10\n")
+	os.Setenv("WATCHNAME", "first")
+	defer os.Unsetenv("WATCHNAME")
+
+	s := New()
+	s.SetConfigFile("CONFIG", "", "config")
+	s.SetString("WATCHLEVEL", "warn", "level")
+	s.SetInt("WATCHRATE", 1, "rate")
+	s.SetString("WATCHNAME", "default", "name")
+	s.SetString("WATCHFLAG", "default", "flag")
+	s.AddRules("WATCHRATE", Max(100))
+	if err := s.ParseArgs([]string{"-config", path, "-watchflag", "fixed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string][2]interface{}{}
+	for _, name := range []string{"WATCHLEVEL", "WATCHRATE", "WATCHNAME", "WATCHFLAG"} {
+		name := name
+		s.OnChange(name, func(old, new interface{}) {
+			if s.Get("WATCHLEVEL") != "debug" {
+				t.Error("callback ran before all values were swapped")
+			}
+			changes[name] = [2]interface{}{old, new}
+		})
+	}
+
+	if err := os.WriteFile(path, []byte("watchlevel: debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("WATCHNAME", "second")
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][2]interface{}{
+		"WATCHLEVEL": {"info", "debug"},
+		"WATCHRATE":  {10, 1},
+		"WATCHNAME":  {"first", "second"},
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+	for name, change := range expected {
+		if changes[name] != change {
+			t.Errorf("%s changed %v, want %v", name, changes[name], change)
+		}
+	}
+	if s.Get("WATCHFLAG") != "fixed" || s.Source("WATCHFLAG").Kind != SourceFlag {
+		t.Error("command line not reapplied", s.Get("WATCHFLAG"), s.Source("WATCHFLAG"))
+	}
+	if s.Source("WATCHRATE").Kind != SourceDefault {
+		t.Error(s.Source("WATCHRATE"))
+	}
+
+	if err := os.WriteFile(path, []byte("watchrate: 1000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var parseErrs ParseErrors
+	if err := s.Reload(); !errors.As(err, &parseErrs) {
+		t.Fatal("expected a validation error, got", err)
+	}
+	if s.Get("WATCHLEVEL") != "debug" || s.GetInt("WATCHRATE") != 1 {
+		t.Error("failed reload changed the values")
+	}
+}
+
+func TestWatchReloadsOnFileModification(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{"watchport": 1}`)
+
+	s := New(WithWatchInterval(10 * time.Millisecond))
+	s.SetInt("WATCHPORT", 0, "port")
+	if err := s.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan interface{}, 1)
+	s.OnChange("WATCHPORT", func(old, new interface{}) {
+		changed <- new
+	})
+	reloadErrors := make(chan error, 1)
+	s.OnReloadError(func(err error) {
+		reloadErrors <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(ctx)
+	}()
+
+	if err := os.WriteFile(path, []byte(`{"watchport": 2}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Keep moving the modification time forward, as Watch may take its first
+	// look at the file only after it was written.
+	touch := time.NewTicker(20 * time.Millisecond)
+	defer touch.Stop()
+	timeout := time.After(5 * time.Second)
+	modified := time.Now()
+wait:
+	for {
+		select {
+		case val := <-changed:
+			if val != 2 {
+				t.Error(val, " != ", 2)
+			}
+			break wait
+		case err := <-reloadErrors:
+			t.Fatal("unexpected reload error", err)
+		case <-touch.C:
+			modified = modified.Add(time.Second)
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("no reload after the file was modified")
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Error("unexpected error", err)
+	}
+	if s.GetInt("WATCHPORT") != 2 {
+		t.Error(s.GetInt("WATCHPORT"), " != ", 2)
+	}
+}
+
+func TestReloadKeepsValuesSetDuringReload(t *testing.T) {
+	os.Setenv("RELOADHOST", "env")
+	defer os.Unsetenv("RELOADHOST")
+
+	s := New()
+	host := Register(s, "reloadhost", "default", "host")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	s.mu.RLock()
+	staged := s.staging()
+	staged.reparse()
+	parsedFrom := s.values()
+	s.mu.RUnlock()
+
+	host.Set("code")
+	s.swap(staged, parsedFrom)
+	if host.Get() != "code" {
+		t.Error(host.Get(), " != ", "code")
+	}
+	if host.Source().Kind != SourceCode {
+		t.Error(host.Source().Kind, " != ", SourceCode)
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	s := New()
+	s.SetString("RELOADNAME", "default", "name")
+	s.SetDuration("RELOADWAIT", time.Second, "wait")
+	if err := s.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Reload(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Get("RELOADNAME") != "default" || s.GetDuration("RELOADWAIT") != time.Second {
+		t.Error(s.Get("RELOADNAME"), s.GetDuration("RELOADWAIT"), " != ", "default", time.Second)
+	}
+}
+
+func TestStagingSharesRegistration(t *testing.T) {
+	s := New()
+	s.SetString("STAGED", "default", "staged")
+	s.SetGroup("group", "STAGED")
+	if err := s.ParseArgs([]string{"-staged", "flag"}); err != nil {
+		t.Fatal(err)
+	}
+	staged := s.staging()
+	if staged.Get("STAGED") != "default" {
+		t.Error(staged.Get("STAGED"), " != ", "default")
+	}
+	if staged.groups["staged"] != "group" || staged.msg["staged"] != "staged" {
+		t.Error(staged.groups, staged.msg, " do not share the registration")
+	}
+	if s.Get("STAGED") != "flag" {
+		t.Error(s.Get("STAGED"), " != ", "flag")
+	}
+}